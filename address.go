@@ -1,12 +1,16 @@
 package golang_gorm
 
-import "time"
+import (
+	"gorm.io/gorm"
+	"time"
+)
 
 type Address struct {
-	ID        int64     `gorm:"primary_key;column:id;autoIncrement"`
-	UserId    string    `gorm:"column:user_id"`
-	Address   string    `gorm:"column:address"`
-	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
-	User      User      `gorm:"foreignKey:user_id;references:id"`
+	ID        int64          `gorm:"primary_key;column:id;autoIncrement"`
+	UserId    string         `gorm:"column:user_id;size:100"`
+	Address   string         `gorm:"column:address"`
+	CreatedAt time.Time      `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time      `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;index"`
+	User      User           `gorm:"foreignKey:user_id;references:id"`
 }
@@ -0,0 +1,35 @@
+package golang_gorm
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+type AddressRepository interface {
+	FindByID(ctx context.Context, id int64) (Address, error)
+	// FindAll lists addresses; pass WithDeleted or OnlyDeleted to see deleted
+	// ones.
+	FindAll(ctx context.Context, scopes ...Scope) ([]Address, error)
+	FindByUserID(ctx context.Context, userID string) ([]Address, error)
+	Create(ctx context.Context, address *Address) error
+	Update(ctx context.Context, address *Address) error
+	// Delete soft deletes the address; Restore brings it back.
+	Delete(ctx context.Context, id int64) error
+	Restore(ctx context.Context, id int64) error
+}
+
+type addressRepository struct {
+	*Repository[Address, int64]
+}
+
+func NewAddressRepository(db *gorm.DB) AddressRepository {
+	return &addressRepository{Repository: NewRepository[Address, int64](db)}
+}
+
+func (r *addressRepository) FindByUserID(ctx context.Context, userID string) ([]Address, error) {
+	return r.FindAll(ctx, Where("user_id = ?", userID), OrderBy("id asc"))
+}
+
+func (r *addressRepository) Update(ctx context.Context, address *Address) error {
+	return r.Save(ctx, address)
+}
@@ -0,0 +1,354 @@
+package golang_gorm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"reflect"
+)
+
+// SystemActor is logged as the acting user when the context names none.
+const SystemActor = "system"
+
+const auditBeforeKey = "audit:before"
+
+// AuditedTables are the tables NewAuditPlugin watches by default.
+var AuditedTables = []string{"users", "wallets", "addresses", "products"}
+
+type actorKey struct{}
+
+// WithActor returns a context naming the user on whose behalf changes are
+// made, for the audit trail.
+func WithActor(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, actorKey{}, userID)
+}
+
+// ActorFromContext returns the user set by WithActor.
+func ActorFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(actorKey{}).(string)
+	return userID, ok && userID != ""
+}
+
+// AuditPlugin writes a UserLog row in the same transaction as every create,
+// update and delete on the audited tables. Creates log the new columns,
+// deletes the removed ones, and updates only the columns that changed, as
+// {"column": {"old": ..., "new": ...}}. Passwords are never logged.
+//
+// Creates that ignore the row (ON CONFLICT DO NOTHING) log nothing, and
+// upserts log the rows they overwrite as updates.
+//
+// Updates, deletes and upserts read the affected rows first, so they cost an
+// extra query or two; changes through raw SQL (Exec) are not seen at all.
+type AuditPlugin struct {
+	tables map[string]bool
+}
+
+func NewAuditPlugin(tables ...string) *AuditPlugin {
+	if len(tables) == 0 {
+		tables = AuditedTables
+	}
+	plugin := &AuditPlugin{tables: map[string]bool{}}
+	for _, table := range tables {
+		plugin.tables[table] = true
+	}
+	return plugin
+}
+
+func (p *AuditPlugin) Name() string {
+	return "audit"
+}
+
+func (p *AuditPlugin) Initialize(db *gorm.DB) error {
+	err := db.Callback().Create().After("gorm:before_create").Before("gorm:create").
+		Register("audit:before_create", p.snapshotUpsert)
+	if err != nil {
+		return err
+	}
+	err = db.Callback().Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").
+		Register("audit:after_create", p.afterCreate)
+	if err != nil {
+		return err
+	}
+	err = db.Callback().Update().After("gorm:before_update").Before("gorm:update").
+		Register("audit:before_update", p.snapshot)
+	if err != nil {
+		return err
+	}
+	err = db.Callback().Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").
+		Register("audit:after_update", p.afterUpdate)
+	if err != nil {
+		return err
+	}
+	err = db.Callback().Delete().After("gorm:before_delete").Before("gorm:delete").
+		Register("audit:before_delete", p.snapshot)
+	if err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Before("gorm:commit_or_rollback_transaction").
+		Register("audit:after_delete", p.afterDelete)
+}
+
+func (p *AuditPlugin) audited(db *gorm.DB) bool {
+	return db.Error == nil && !db.DryRun && db.Statement.Schema != nil &&
+		p.tables[db.Statement.Schema.Table] && db.Statement.Schema.PrioritizedPrimaryField != nil
+}
+
+// snapshotUpsert keeps the rows an ON CONFLICT create may overwrite.
+func (p *AuditPlugin) snapshotUpsert(db *gorm.DB) {
+	if !p.audited(db) || db.Statement.ReflectValue.Kind() == reflect.Map {
+		return
+	}
+	if _, ok := db.Statement.Clauses["ON CONFLICT"]; !ok {
+		return
+	}
+	before, err := p.findCreated(db)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	db.InstanceSet(auditBeforeKey, before)
+}
+
+func (p *AuditPlugin) afterCreate(db *gorm.DB) {
+	if !p.audited(db) || db.RowsAffected == 0 || db.Statement.ReflectValue.Kind() == reflect.Map {
+		return
+	}
+	sch := db.Statement.Schema
+	value, upsert := db.InstanceGet(auditBeforeKey)
+	if !upsert {
+		for _, row := range rows(db.Statement.ReflectValue) {
+			p.write(db, UserLogCreate, row, columns(db, sch, row, nil))
+		}
+		return
+	}
+
+	// The statement may have inserted, overwritten or skipped each row, so
+	// what is stored now is compared with what was there before.
+	after, err := p.findCreated(db)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	old := map[interface{}]reflect.Value{}
+	for _, row := range value.([]reflect.Value) {
+		id, _ := sch.PrioritizedPrimaryField.ValueOf(db.Statement.Context, row)
+		old[id] = row
+	}
+	for _, row := range after {
+		id, _ := sch.PrioritizedPrimaryField.ValueOf(db.Statement.Context, row)
+		previous, ok := old[id]
+		if !ok {
+			p.write(db, UserLogCreate, row, columns(db, sch, row, nil))
+			continue
+		}
+		if diff := columns(db, sch, row, &previous); len(diff) > 0 {
+			p.write(db, UserLogUpdate, row, diff)
+		}
+	}
+}
+
+// snapshot keeps the rows an update or delete is about to touch.
+func (p *AuditPlugin) snapshot(db *gorm.DB) {
+	if !p.audited(db) {
+		return
+	}
+	before, err := p.load(db)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	db.InstanceSet(auditBeforeKey, before)
+}
+
+func (p *AuditPlugin) afterUpdate(db *gorm.DB) {
+	if !p.audited(db) || db.RowsAffected == 0 {
+		return
+	}
+	value, ok := db.InstanceGet(auditBeforeKey)
+	if !ok {
+		return
+	}
+	before := value.([]reflect.Value)
+
+	sch := db.Statement.Schema
+	ids := make([]interface{}, len(before))
+	for i, row := range before {
+		ids[i], _ = sch.PrioritizedPrimaryField.ValueOf(db.Statement.Context, row)
+	}
+	after, err := p.find(db, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where(map[string]interface{}{sch.PrioritizedPrimaryField.DBName: ids})
+	})
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+
+	old := map[interface{}]reflect.Value{}
+	for _, row := range before {
+		id, _ := sch.PrioritizedPrimaryField.ValueOf(db.Statement.Context, row)
+		old[id] = row
+	}
+	for _, row := range after {
+		id, _ := sch.PrioritizedPrimaryField.ValueOf(db.Statement.Context, row)
+		previous, ok := old[id]
+		if !ok {
+			continue
+		}
+		if diff := columns(db, sch, row, &previous); len(diff) > 0 {
+			p.write(db, UserLogUpdate, row, diff)
+		}
+	}
+}
+
+func (p *AuditPlugin) afterDelete(db *gorm.DB) {
+	if !p.audited(db) || db.RowsAffected == 0 {
+		return
+	}
+	value, ok := db.InstanceGet(auditBeforeKey)
+	if !ok {
+		return
+	}
+	for _, row := range value.([]reflect.Value) {
+		p.write(db, UserLogDelete, row, columns(db, db.Statement.Schema, row, nil))
+	}
+}
+
+// load reads the rows matched by the statement's primary key or conditions,
+// the way the update or delete itself is about to select them.
+func (p *AuditPlugin) load(db *gorm.DB) ([]reflect.Value, error) {
+	stmt := db.Statement
+	sch := stmt.Schema
+
+	var ids []interface{}
+	for _, row := range rows(stmt.ReflectValue) {
+		if id, zero := sch.PrioritizedPrimaryField.ValueOf(stmt.Context, row); !zero {
+			ids = append(ids, id)
+		}
+	}
+	where, hasWhere := stmt.Clauses["WHERE"]
+	if len(ids) == 0 && !hasWhere {
+		return nil, nil
+	}
+
+	return p.find(db, func(tx *gorm.DB) *gorm.DB {
+		if len(ids) > 0 {
+			tx = tx.Where(map[string]interface{}{sch.PrioritizedPrimaryField.DBName: ids})
+		}
+		if hasWhere {
+			tx = tx.Clauses(where.Expression)
+		}
+		return tx
+	})
+}
+
+// findCreated reads the stored rows with the primary keys of the rows being
+// created, soft deleted or not, as ON CONFLICT sees them. Rows whose key the
+// database generates are not found before the insert.
+func (p *AuditPlugin) findCreated(db *gorm.DB) ([]reflect.Value, error) {
+	stmt := db.Statement
+	primary := stmt.Schema.PrioritizedPrimaryField
+	var ids []interface{}
+	for _, row := range rows(stmt.ReflectValue) {
+		if id, zero := primary.ValueOf(stmt.Context, row); !zero {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return p.find(db, func(tx *gorm.DB) *gorm.DB {
+		return tx.Unscoped().Where(map[string]interface{}{primary.DBName: ids})
+	})
+}
+
+func (p *AuditPlugin) find(db *gorm.DB, scope Scope) ([]reflect.Value, error) {
+	sch := db.Statement.Schema
+	found := reflect.New(reflect.SliceOf(sch.ModelType))
+	tx := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Model(reflect.New(sch.ModelType).Interface())
+	// Soft deleted rows are only touched by statements that are Unscoped.
+	if db.Statement.Unscoped {
+		tx = tx.Unscoped()
+	}
+	err := tx.Scopes(scope).Find(found.Interface()).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows(found.Elem()), nil
+}
+
+func (p *AuditPlugin) write(db *gorm.DB, action UserLogAction, row reflect.Value, payload map[string]interface{}) {
+	stmt := db.Statement
+	content, err := json.Marshal(payload)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+
+	actor, ok := ActorFromContext(stmt.Context)
+	if !ok {
+		actor = SystemActor
+	}
+	id, _ := stmt.Schema.PrioritizedPrimaryField.ValueOf(stmt.Context, row)
+	err = db.Session(&gorm.Session{NewDB: true}).Create(&UserLog{
+		UserId:     actor,
+		Action:     action,
+		EntityType: stmt.Schema.Table,
+		EntityId:   fmt.Sprint(id),
+		Payload:    content,
+	}).Error
+	if err != nil {
+		db.AddError(err)
+	}
+}
+
+// columns returns the row's column values, or with previous only those that
+// differ from it as old/new pairs. Columns maintained by autoUpdateTime are
+// left out of diffs.
+func columns(db *gorm.DB, sch *schema.Schema, row reflect.Value, previous *reflect.Value) map[string]interface{} {
+	ctx := db.Statement.Context
+	values := map[string]interface{}{}
+	for _, field := range sch.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		value, _ := field.ValueOf(ctx, row)
+		if previous == nil {
+			values[field.DBName] = redact(field, value)
+			continue
+		}
+
+		old, _ := field.ValueOf(ctx, *previous)
+		if field.AutoUpdateTime > 0 || reflect.DeepEqual(old, value) {
+			continue
+		}
+		values[field.DBName] = map[string]interface{}{"old": redact(field, old), "new": redact(field, value)}
+	}
+	return values
+}
+
+func redact(field *schema.Field, value interface{}) interface{} {
+	if field.FieldType == reflect.TypeOf(Password("")) {
+		return Password("").String()
+	}
+	return value
+}
+
+// rows lists the structs in a struct, pointer or slice value.
+func rows(value reflect.Value) []reflect.Value {
+	value = reflect.Indirect(value)
+	switch value.Kind() {
+	case reflect.Struct:
+		return []reflect.Value{value}
+	case reflect.Slice, reflect.Array:
+		var found []reflect.Value
+		for i := 0; i < value.Len(); i++ {
+			if row := reflect.Indirect(value.Index(i)); row.Kind() == reflect.Struct {
+				found = append(found, row)
+			}
+		}
+		return found
+	}
+	return nil
+}
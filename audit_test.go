@@ -0,0 +1,124 @@
+package golang_gorm
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"testing"
+)
+
+func auditTrail(t *testing.T, db *gorm.DB, entityType, entityID string) []UserLog {
+	logs, err := NewRepository[UserLog, int](db).FindAll(context.Background(), Where("entity_type = ? AND entity_id = ?", entityType, entityID), OrderBy("id asc"))
+	assert.Nil(t, err)
+	return logs
+}
+
+func auditPayload(t *testing.T, log UserLog) map[string]interface{} {
+	var payload map[string]interface{}
+	err := json.Unmarshal(log.Payload, &payload)
+	assert.Nil(t, err)
+	return payload
+}
+
+func TestAuditTrail(t *testing.T) {
+	db := newTestDB(t)
+	repositories := NewRepositories(db)
+	ctx := WithActor(context.Background(), "1")
+
+	user := User{ID: "50", Password: "rahasia", Name: Name{FirstName: "User 50"}}
+	err := repositories.Users.Create(ctx, &user)
+	assert.Nil(t, err)
+
+	err = FromContext(ctx, db).Model(&user).Updates(map[string]interface{}{"first_name": "Renamed", "password": "baru"}).Error
+	assert.Nil(t, err)
+	// Nothing changes, so nothing is logged.
+	err = FromContext(ctx, db).Model(&user).Update("first_name", "Renamed").Error
+	assert.Nil(t, err)
+
+	err = repositories.Users.Delete(context.Background(), "50")
+	assert.Nil(t, err)
+
+	logs := auditTrail(t, db, "users", "50")
+	assert.Equal(t, 3, len(logs))
+
+	assert.Equal(t, UserLogCreate, logs[0].Action)
+	assert.Equal(t, "1", logs[0].UserId)
+	created := auditPayload(t, logs[0])
+	assert.Equal(t, "User 50", created["first_name"])
+	assert.Equal(t, "[REDACTED]", created["password"])
+
+	assert.Equal(t, UserLogUpdate, logs[1].Action)
+	updated := auditPayload(t, logs[1])
+	assert.Equal(t, map[string]interface{}{"old": "User 50", "new": "Renamed"}, updated["first_name"])
+	assert.Equal(t, map[string]interface{}{"old": "[REDACTED]", "new": "[REDACTED]"}, updated["password"])
+	assert.NotContains(t, updated, "updated_at")
+	assert.NotContains(t, updated, "last_name")
+
+	assert.Equal(t, UserLogDelete, logs[2].Action)
+	assert.Equal(t, SystemActor, logs[2].UserId)
+	assert.Equal(t, "Renamed", auditPayload(t, logs[2])["first_name"])
+}
+
+func TestAuditTrailBatchAndTransfers(t *testing.T) {
+	db := newTestDB(t, "users", "wallets", "products")
+	repositories := NewRepositories(db)
+	ctx := WithActor(context.Background(), "2")
+
+	err := FromContext(ctx, db).Model(&Product{}).Where("price > ?", 0).Update("price", 1).Error
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(auditTrail(t, db, "products", "P001")))
+	assert.Equal(t, 1, len(auditTrail(t, db, "products", "P002")))
+
+	_, err = NewTransferService(db).Transfer(ctx, "01", "1", 1000)
+	assert.Nil(t, err)
+	logs := auditTrail(t, db, "wallets", "01")
+	assert.Equal(t, 1, len(logs))
+	assert.Equal(t, "2", logs[0].UserId)
+	assert.Equal(t, map[string]interface{}{"old": float64(1000000), "new": float64(999000)}, auditPayload(t, logs[0])["balance"])
+
+	// Rolled back changes leave no trail.
+	_, err = NewTransferService(db).Transfer(ctx, "01", "1", 100000000)
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, len(auditTrail(t, db, "wallets", "01")))
+
+	// Tables outside the audited set are not logged.
+	err = repositories.GuestBooks.Create(ctx, &GuestBook{Name: "Guest"})
+	assert.Nil(t, err)
+	var count int64
+	err = db.Model(&UserLog{}).Where("entity_type = ?", "guest_books").Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestAuditTrailUpsert(t *testing.T) {
+	db := newTestDB(t, "products")
+	products := NewRepository[Product, string](db)
+	ctx := context.Background()
+
+	err := products.Upsert(ctx, []Product{
+		{ID: "P001", Name: "Contoh Produk", Price: 1500000},
+		{ID: "P003", Name: "Contoh Produk 3", Price: 3000000},
+	})
+	assert.Nil(t, err)
+	logs := auditTrail(t, db, "products", "P001")
+	assert.Equal(t, 1, len(logs))
+	assert.Equal(t, UserLogUpdate, logs[0].Action)
+	updated := auditPayload(t, logs[0])
+	assert.Equal(t, map[string]interface{}{"old": float64(1000000), "new": float64(1500000)}, updated["price"])
+	assert.NotContains(t, updated, "name")
+	logs = auditTrail(t, db, "products", "P003")
+	assert.Equal(t, 1, len(logs))
+	assert.Equal(t, UserLogCreate, logs[0].Action)
+
+	// Overwriting a row with what it holds changes nothing.
+	err = products.Upsert(ctx, []Product{{ID: "P002", Name: "Contoh Produk 2"}}, "name")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(auditTrail(t, db, "products", "P002")))
+
+	// A skipped insert is not a create.
+	err = db.Clauses(clause.OnConflict{DoNothing: true}).Create(&Product{ID: "P003", Name: "Other"}).Error
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(auditTrail(t, db, "products", "P003")))
+}
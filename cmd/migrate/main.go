@@ -0,0 +1,228 @@
+// Command migrate manages the versioned schema of the golang_gorm database.
+//
+//	migrate [flags] up           apply every pending migration
+//	migrate [flags] down N       revert the N latest migrations
+//	migrate [flags] redo         revert and re-apply the latest migration
+//	migrate [flags] status       list migrations and when they were applied
+//	migrate [flags] create NAME  write empty NNNN_NAME.up.sql/.down.sql files
+//	migrate [flags] schema       print the DDL GORM's Migrator would run for the models
+//
+// With -dry-run, up, down and redo print the SQL they would execute instead of
+// running it, and never write to the database: a missing schema_migrations
+// table counts as nothing applied. up follows its SQL with the DDL of schema,
+// which the applied migrations should match. schema never needs a database and
+// is always a dry run.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	golang_gorm "golang-gorm"
+	"gorm.io/driver/mysql"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"text/tabwriter"
+)
+
+var namePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+func main() {
+	configPath := flag.String("config", "", "YAML configuration file, overridden by DB_* environment variables")
+	dir := flag.String("dir", "", "read migrations from this directory instead of the embedded ones")
+	dryRun := flag.Bool("dry-run", false, "print the SQL instead of executing it")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: migrate [flags] up | down N | redo | status | create NAME | schema")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(*configPath, *dir, *dryRun, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "migrate:", err)
+		os.Exit(1)
+	}
+}
+
+func run(configPath, dir string, dryRun bool, args []string) error {
+	if len(args) == 0 {
+		flag.Usage()
+		return errors.New("missing command")
+	}
+
+	config, err := golang_gorm.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "create":
+		if len(args) != 2 || !namePattern.MatchString(args[1]) {
+			return errors.New("create needs one lowercase snake_case NAME")
+		}
+		if dir == "" {
+			dir = "migrations"
+		}
+		return create(dir, args[1])
+	case "schema":
+		return schema(config)
+	}
+
+	migrations, err := migrationFiles(dir)
+	if err != nil {
+		return err
+	}
+	db, err := golang_gorm.OpenConnection(config)
+	if err != nil {
+		return err
+	}
+	newRunner := golang_gorm.NewMigrationRunner
+	if dryRun || args[0] == "status" {
+		newRunner = golang_gorm.NewReadOnlyMigrationRunner
+	}
+	runner, err := newRunner(db, migrations)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		if dryRun {
+			pending, err := runner.Pending()
+			if err != nil {
+				return err
+			}
+			printMigrations(pending, true)
+			return schema(config)
+		}
+		applied, err := runner.Up()
+		report("applied", applied)
+		return err
+	case "down":
+		if len(args) != 2 {
+			return errors.New("down needs the number of migrations to revert")
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid number of migrations %q", args[1])
+		}
+		if dryRun {
+			latest, err := runner.Latest(n)
+			if err != nil {
+				return err
+			}
+			printMigrations(latest, false)
+			return nil
+		}
+		reverted, err := runner.Down(n)
+		report("reverted", reverted)
+		return err
+	case "redo":
+		if dryRun {
+			latest, err := runner.Latest(1)
+			if err != nil {
+				return err
+			}
+			printMigrations(latest, false)
+			printMigrations(latest, true)
+			return nil
+		}
+		migration, err := runner.Redo()
+		if err != nil {
+			return err
+		}
+		report("redone", []golang_gorm.Migration{migration})
+		return nil
+	case "status":
+		return status(runner)
+	default:
+		flag.Usage()
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+func migrationFiles(dir string) (fs.FS, error) {
+	if dir != "" {
+		return os.DirFS(dir), nil
+	}
+	return fs.Sub(golang_gorm.MigrationFiles, "migrations")
+}
+
+func create(dir, name string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	version, err := golang_gorm.NextMigrationVersion(os.DirFS(dir))
+	if err != nil {
+		return err
+	}
+
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, golang_gorm.MigrationFileName(version, name, direction))
+		content := fmt.Sprintf("-- %04d_%s %s migration\n", version, name, direction)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return err
+		}
+		fmt.Println("created", path)
+	}
+	return nil
+}
+
+func schema(config golang_gorm.Config) error {
+	dsn, err := config.DSN()
+	if err != nil {
+		return err
+	}
+	statements, err := golang_gorm.ModelSQL(mysql.New(mysql.Config{
+		DSN:                       dsn,
+		SkipInitializeWithVersion: true,
+	}), golang_gorm.Models()...)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("-- DDL GORM's Migrator would run for the models")
+	for _, statement := range statements {
+		fmt.Printf("%s;\n\n", statement)
+	}
+	return nil
+}
+
+func status(runner *golang_gorm.MigrationRunner) error {
+	statuses, err := runner.Status()
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.Applied {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(writer, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+	return writer.Flush()
+}
+
+func printMigrations(migrations []golang_gorm.Migration, up bool) {
+	for _, migration := range migrations {
+		direction, script := "down", migration.Down
+		if up {
+			direction, script = "up", migration.Up
+		}
+		fmt.Printf("-- %04d_%s %s\n", migration.Version, migration.Name, direction)
+		for _, statement := range golang_gorm.SplitStatements(script) {
+			fmt.Printf("%s;\n\n", statement)
+		}
+	}
+}
+
+func report(verb string, migrations []golang_gorm.Migration) {
+	for _, migration := range migrations {
+		fmt.Printf("%s %04d_%s\n", verb, migration.Version, migration.Name)
+	}
+}
@@ -0,0 +1,73 @@
+// Command purge permanently deletes soft deleted rows whose deleted_at is
+// older than -older-than, from every soft deletable table.
+//
+//	purge [flags]
+//
+// Rows that other rows still point at, such as wallets with ledger movements,
+// are kept and reported as blocked. With -dry-run nothing is deleted.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	golang_gorm "golang-gorm"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+func main() {
+	configPath := flag.String("config", "", "YAML configuration file, overridden by DB_* environment variables")
+	olderThan := flag.Duration("older-than", 30*24*time.Hour, "purge rows deleted longer ago than this")
+	batchSize := flag.Int("batch", golang_gorm.DefaultPurgeBatchSize, "rows deleted per transaction")
+	pause := flag.Duration("pause", 100*time.Millisecond, "sleep between batches")
+	dryRun := flag.Bool("dry-run", false, "only report what would be purged")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: purge [flags]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(*configPath, *olderThan, *batchSize, *pause, *dryRun); err != nil {
+		fmt.Fprintln(os.Stderr, "purge:", err)
+		os.Exit(1)
+	}
+}
+
+func run(configPath string, olderThan time.Duration, batchSize int, pause time.Duration, dryRun bool) error {
+	config, err := golang_gorm.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	db, err := golang_gorm.OpenConnection(config)
+	if err != nil {
+		return err
+	}
+
+	purger, err := golang_gorm.NewPurger(db, olderThan)
+	if err != nil {
+		return err
+	}
+	purger.BatchSize = batchSize
+	purger.Pause = pause
+	purger.DryRun = dryRun
+
+	reports, err := purger.Run(context.Background())
+	report(dryRun, reports)
+	return err
+}
+
+func report(dryRun bool, reports []golang_gorm.PurgeReport) {
+	verb := "PURGED"
+	if dryRun {
+		verb = "WOULD PURGE"
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(writer, "TABLE\t%s\tBLOCKED\t\n", verb)
+	for _, r := range reports {
+		fmt.Fprintf(writer, "%s\t%d\t%d\t\n", r.Table, r.Purged, r.Blocked)
+	}
+	writer.Flush()
+}
@@ -0,0 +1,83 @@
+// Command reconcile compares every wallet balance with the sum of its
+// movements and lists the wallets that drifted.
+//
+//	reconcile [flags] [WALLET_ID...]
+//
+// With -repair, each drifted balance is set back to its ledger while the
+// wallet row is locked. The exit status is 1 when drift was found and left
+// unrepaired.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	golang_gorm "golang-gorm"
+	"os"
+	"text/tabwriter"
+)
+
+func main() {
+	configPath := flag.String("config", "", "YAML configuration file, overridden by DB_* environment variables")
+	repair := flag.Bool("repair", false, "set drifted balances back to their ledger")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: reconcile [flags] [WALLET_ID...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	drifted, err := run(*configPath, *repair, flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "reconcile:", err)
+		os.Exit(1)
+	}
+	if drifted {
+		os.Exit(1)
+	}
+}
+
+func run(configPath string, repair bool, walletIDs []string) (bool, error) {
+	config, err := golang_gorm.LoadConfig(configPath)
+	if err != nil {
+		return false, err
+	}
+	db, err := golang_gorm.OpenConnection(config)
+	if err != nil {
+		return false, err
+	}
+
+	reconciler := golang_gorm.NewReconciler(db)
+	ctx := context.Background()
+	if repair {
+		repaired, err := reconciler.RepairAll(ctx, walletIDs...)
+		report("repaired", repaired)
+		return false, err
+	}
+
+	mismatches, err := reconciler.Check(ctx, walletIDs...)
+	if err != nil {
+		return false, err
+	}
+	report("drifted", mismatches)
+	return len(mismatches) > 0, nil
+}
+
+func report(verb string, mismatches []golang_gorm.WalletMismatch) {
+	if len(mismatches) == 0 {
+		fmt.Println("all wallets match their ledger")
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(writer, "WALLET\tUSER\tBALANCE\tLEDGER\tDIFFERENCE\tMOVEMENTS\tUPDATED AT\t")
+	for _, m := range mismatches {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%d\t%s\t\n",
+			m.WalletId, m.UserId,
+			golang_gorm.Money{Amount: m.Balance, Currency: m.Currency},
+			golang_gorm.Money{Amount: m.Ledger, Currency: m.Currency},
+			golang_gorm.Money{Amount: m.Difference(), Currency: m.Currency},
+			m.Movements, m.UpdatedAt.Format("2006-01-02 15:04:05"))
+	}
+	writer.Flush()
+	fmt.Printf("%s %d wallet(s)\n", verb, len(mismatches))
+}
@@ -0,0 +1,79 @@
+// Command retention moves user_logs older than -max-age out of the hot table,
+// into user_log_archives or, with -dir, into gzipped NDJSON files.
+//
+//	retention [flags]
+//	retention [flags] -restore FROM TO
+//
+// With -restore, the archived entries created between the two RFC 3339 times
+// are moved back into user_logs instead.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	golang_gorm "golang-gorm"
+	"os"
+	"time"
+)
+
+func main() {
+	configPath := flag.String("config", "", "YAML configuration file, overridden by DB_* environment variables")
+	maxAge := flag.Duration("max-age", 90*24*time.Hour, "archive entries older than this")
+	batchSize := flag.Int("batch", golang_gorm.DefaultRetentionBatchSize, "entries moved per transaction")
+	pause := flag.Duration("pause", 100*time.Millisecond, "sleep between batches")
+	dir := flag.String("dir", "", "archive into files in this directory instead of user_log_archives")
+	restore := flag.Bool("restore", false, "move the entries archived between FROM and TO back")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: retention [flags] [-restore FROM TO]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(*configPath, *maxAge, *batchSize, *pause, *dir, *restore, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "retention:", err)
+		os.Exit(1)
+	}
+}
+
+func run(configPath string, maxAge time.Duration, batchSize int, pause time.Duration, dir string, restore bool, args []string) error {
+	config, err := golang_gorm.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	db, err := golang_gorm.OpenConnection(config)
+	if err != nil {
+		return err
+	}
+
+	var store golang_gorm.ArchiveStore = golang_gorm.NewTableArchive(db)
+	if dir != "" {
+		store = golang_gorm.NewFileArchive(dir)
+	}
+	retention := golang_gorm.NewUserLogRetention(db, store, maxAge)
+	retention.BatchSize = batchSize
+	retention.Pause = pause
+	ctx := context.Background()
+
+	if !restore {
+		archived, err := retention.Run(ctx)
+		fmt.Printf("archived %d entries\n", archived)
+		return err
+	}
+
+	if len(args) != 2 {
+		return errors.New("-restore needs FROM and TO")
+	}
+	from, err := time.Parse(time.RFC3339, args[0])
+	if err != nil {
+		return err
+	}
+	to, err := time.Parse(time.RFC3339, args[1])
+	if err != nil {
+		return err
+	}
+	restored, err := retention.Restore(ctx, from.UnixMilli(), to.UnixMilli())
+	fmt.Printf("restored %d entries\n", restored)
+	return err
+}
@@ -0,0 +1,299 @@
+package golang_gorm
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	gomysql "github.com/go-sql-driver/mysql"
+	"gopkg.in/yaml.v3"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config describes how to open the MySQL database used by the models.
+type Config struct {
+	User     string    `yaml:"user"`
+	Password string    `yaml:"password"`
+	Host     string    `yaml:"host"`
+	Port     int       `yaml:"port"`
+	Database string    `yaml:"database"`
+	Charset  string    `yaml:"charset"`
+	TimeZone string    `yaml:"time_zone"`
+	TLS      TLSConfig `yaml:"tls"`
+
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+
+	LogLevel string `yaml:"log_level"`
+}
+
+// TLSConfig selects the TLS mode of the connection. Mode accepts the values
+// understood by go-sql-driver/mysql ("false", "true", "skip-verify",
+// "preferred"); when CAFile is set a custom configuration is registered.
+type TLSConfig struct {
+	Mode       string `yaml:"mode"`
+	CAFile     string `yaml:"ca_file"`
+	CertFile   string `yaml:"cert_file"`
+	KeyFile    string `yaml:"key_file"`
+	ServerName string `yaml:"server_name"`
+}
+
+const tlsConfigName = "golang-gorm"
+
+// DefaultConfig returns the settings the project has always used for local
+// development.
+func DefaultConfig() Config {
+	return Config{
+		User:            "root",
+		Password:        "123",
+		Host:            "localhost",
+		Port:            3306,
+		Database:        "golang_gorm",
+		Charset:         "utf8mb4",
+		TimeZone:        "Local",
+		MaxOpenConns:    100,
+		MaxIdleConns:    10,
+		ConnMaxLifetime: 30 * time.Minute,
+		ConnMaxIdleTime: 5 * time.Minute,
+		LogLevel:        "info",
+	}
+}
+
+// LoadConfig starts from DefaultConfig, overlays the YAML file at path (if
+// path is not empty) and finally the DB_* environment variables.
+func LoadConfig(path string) (Config, error) {
+	config := DefaultConfig()
+
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+		if err := yaml.Unmarshal(content, &config); err != nil {
+			return Config{}, fmt.Errorf("parse %s: %w", path, err)
+		}
+	}
+
+	if err := config.applyEnv(os.LookupEnv); err != nil {
+		return Config{}, err
+	}
+
+	return config, config.Validate()
+}
+
+// LoadConfigFromEnv is LoadConfig without a configuration file.
+func LoadConfigFromEnv() (Config, error) {
+	return LoadConfig("")
+}
+
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	texts := map[string]*string{
+		"DB_USER":            &c.User,
+		"DB_PASSWORD":        &c.Password,
+		"DB_HOST":            &c.Host,
+		"DB_NAME":            &c.Database,
+		"DB_CHARSET":         &c.Charset,
+		"DB_TIMEZONE":        &c.TimeZone,
+		"DB_TLS_MODE":        &c.TLS.Mode,
+		"DB_TLS_CA_FILE":     &c.TLS.CAFile,
+		"DB_TLS_CERT_FILE":   &c.TLS.CertFile,
+		"DB_TLS_KEY_FILE":    &c.TLS.KeyFile,
+		"DB_TLS_SERVER_NAME": &c.TLS.ServerName,
+		"DB_LOG_LEVEL":       &c.LogLevel,
+	}
+	for key, target := range texts {
+		if value, ok := lookup(key); ok {
+			*target = value
+		}
+	}
+
+	ints := map[string]*int{
+		"DB_PORT":           &c.Port,
+		"DB_MAX_OPEN_CONNS": &c.MaxOpenConns,
+		"DB_MAX_IDLE_CONNS": &c.MaxIdleConns,
+	}
+	for key, target := range ints {
+		if value, ok := lookup(key); ok {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			*target = parsed
+		}
+	}
+
+	durations := map[string]*time.Duration{
+		"DB_CONN_MAX_LIFETIME":  &c.ConnMaxLifetime,
+		"DB_CONN_MAX_IDLE_TIME": &c.ConnMaxIdleTime,
+	}
+	for key, target := range durations {
+		if value, ok := lookup(key); ok {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			*target = parsed
+		}
+	}
+
+	return nil
+}
+
+// Validate reports configuration mistakes before any connection is attempted.
+func (c Config) Validate() error {
+	var errs []error
+	if c.Host == "" {
+		errs = append(errs, errors.New("host is required"))
+	}
+	if c.Port <= 0 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("invalid port %d", c.Port))
+	}
+	if c.Database == "" {
+		errs = append(errs, errors.New("database is required"))
+	}
+	if c.MaxIdleConns > c.MaxOpenConns && c.MaxOpenConns > 0 {
+		errs = append(errs, fmt.Errorf("max_idle_conns %d exceeds max_open_conns %d", c.MaxIdleConns, c.MaxOpenConns))
+	}
+	if _, err := c.location(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := c.logLevel(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (c Config) location() (*time.Location, error) {
+	if c.TimeZone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(c.TimeZone)
+}
+
+func (c Config) logLevel() (logger.LogLevel, error) {
+	switch strings.ToLower(c.LogLevel) {
+	case "silent":
+		return logger.Silent, nil
+	case "error":
+		return logger.Error, nil
+	case "warn":
+		return logger.Warn, nil
+	case "", "info":
+		return logger.Info, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q", c.LogLevel)
+	}
+}
+
+// DSN renders the go-sql-driver/mysql data source name for the configuration.
+func (c Config) DSN() (string, error) {
+	loc, err := c.location()
+	if err != nil {
+		return "", err
+	}
+
+	dsn := gomysql.NewConfig()
+	dsn.User = c.User
+	dsn.Passwd = c.Password
+	dsn.Net = "tcp"
+	dsn.Addr = net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+	dsn.DBName = c.Database
+	dsn.ParseTime = true
+	dsn.Loc = loc
+	if c.Charset != "" {
+		dsn.Params = map[string]string{"charset": c.Charset}
+	}
+
+	switch {
+	case c.TLS.CAFile != "":
+		dsn.TLSConfig = tlsConfigName
+	case c.TLS.Mode != "":
+		dsn.TLSConfig = c.TLS.Mode
+	}
+
+	return dsn.FormatDSN(), nil
+}
+
+func (c Config) registerTLS() error {
+	if c.TLS.CAFile == "" {
+		return nil
+	}
+
+	pem, err := os.ReadFile(c.TLS.CAFile)
+	if err != nil {
+		return err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("no certificates found in %s", c.TLS.CAFile)
+	}
+
+	tlsConfig := &tls.Config{
+		RootCAs:            pool,
+		ServerName:         c.TLS.ServerName,
+		InsecureSkipVerify: c.TLS.Mode == "skip-verify",
+	}
+	if c.TLS.CertFile != "" || c.TLS.KeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(c.TLS.CertFile, c.TLS.KeyFile)
+		if err != nil {
+			return err
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return gomysql.RegisterTLSConfig(tlsConfigName, tlsConfig)
+}
+
+// OpenConnection opens the database described by config and applies its pool
+// limits.
+func OpenConnection(config Config) (*gorm.DB, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.registerTLS(); err != nil {
+		return nil, err
+	}
+
+	dsn, err := config.DSN()
+	if err != nil {
+		return nil, err
+	}
+	level, err := config.logLevel()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+		Logger: RedactPasswords(logger.Default.LogMode(level)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Use(NewAuditPlugin()); err != nil {
+		return nil, err
+	}
+	if err := db.Use(NewSoftDeletePlugin()); err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+
+	return db, nil
+}
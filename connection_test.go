@@ -0,0 +1,64 @@
+package golang_gorm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDefaultConfigDSN(t *testing.T) {
+	dsn, err := DefaultConfig().DSN()
+	assert.Nil(t, err)
+	assert.Equal(t, "root:123@tcp(localhost:3306)/golang_gorm?loc=Local&parseTime=true&charset=utf8mb4", dsn)
+}
+
+func TestLoadConfigFileAndEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "database.yaml")
+	err := os.WriteFile(path, []byte(`
+host: db.internal
+port: 3307
+database: orders
+max_open_conns: 20
+max_idle_conns: 5
+conn_max_lifetime: 1h
+log_level: warn
+tls:
+  mode: skip-verify
+`), 0o600)
+	assert.Nil(t, err)
+
+	t.Setenv("DB_USER", "app")
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("DB_TIMEZONE", "Asia/Jakarta")
+
+	config, err := LoadConfig(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "app", config.User)
+	assert.Equal(t, "db.internal", config.Host)
+	assert.Equal(t, 3307, config.Port)
+	assert.Equal(t, 20, config.MaxOpenConns)
+	assert.Equal(t, time.Hour, config.ConnMaxLifetime)
+	assert.Equal(t, 5*time.Minute, config.ConnMaxIdleTime)
+
+	dsn, err := config.DSN()
+	assert.Nil(t, err)
+	assert.Equal(t, "app:secret@tcp(db.internal:3307)/orders?loc=Asia%2FJakarta&parseTime=true&tls=skip-verify&charset=utf8mb4", dsn)
+}
+
+func TestConfigValidate(t *testing.T) {
+	config := DefaultConfig()
+	config.Port = 0
+	config.LogLevel = "verbose"
+	config.MaxIdleConns = 200
+
+	err := config.Validate()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "invalid port 0")
+	assert.Contains(t, err.Error(), "unknown log level")
+	assert.Contains(t, err.Error(), "exceeds max_open_conns")
+
+	_, err = OpenConnection(config)
+	assert.NotNil(t, err)
+}
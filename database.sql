@@ -0,0 +1,202 @@
+CREATE TABLE sample
+(
+    id   VARCHAR(100) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    PRIMARY KEY (id)
+) ENGINE = InnoDB;
+
+CREATE TABLE users
+(
+    id          VARCHAR(100) NOT NULL,
+    password    VARCHAR(100) NOT NULL,
+    first_name  VARCHAR(100) NOT NULL,
+    middle_name VARCHAR(100) NULL,
+    last_name   VARCHAR(100) NULL,
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+) ENGINE = InnoDB;
+
+CREATE TABLE user_logs
+(
+    id         INT          NOT NULL AUTO_INCREMENT,
+    user_id    VARCHAR(100) NOT NULL,
+    action     VARCHAR(100) NOT NULL,
+    created_at BIGINT       NOT NULL,
+    updated_at BIGINT       NOT NULL,
+    PRIMARY KEY (id)
+) ENGINE = InnoDB;
+
+CREATE TABLE todos
+(
+    id          BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id     VARCHAR(100)    NOT NULL,
+    title       VARCHAR(100)    NOT NULL,
+    description TEXT            NULL,
+    created_at  TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at  TIMESTAMP       NULL,
+    PRIMARY KEY (id),
+    INDEX idx_todos_deleted_at (deleted_at)
+) ENGINE = InnoDB;
+
+CREATE TABLE wallets
+(
+    id         VARCHAR(100) NOT NULL,
+    user_id    VARCHAR(100) NULL,
+    balance    BIGINT       NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users_wallet FOREIGN KEY (user_id) REFERENCES users (id)
+) ENGINE = InnoDB;
+
+CREATE TABLE addresses
+(
+    id         BIGINT       NOT NULL AUTO_INCREMENT,
+    user_id    VARCHAR(100) NOT NULL,
+    address    VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users_addresses FOREIGN KEY (user_id) REFERENCES users (id)
+) ENGINE = InnoDB;
+
+CREATE TABLE products
+(
+    id         VARCHAR(100) NOT NULL,
+    name       VARCHAR(100) NOT NULL,
+    price      BIGINT       NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+) ENGINE = InnoDB;
+
+CREATE TABLE user_like_product
+(
+    user_id    VARCHAR(100) NOT NULL,
+    product_id VARCHAR(100) NOT NULL,
+    PRIMARY KEY (user_id, product_id),
+    CONSTRAINT fk_user_like_product_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_user_like_product_product FOREIGN KEY (product_id) REFERENCES products (id)
+) ENGINE = InnoDB;
+
+CREATE TABLE guest_books
+(
+    id         BIGINT       NOT NULL AUTO_INCREMENT,
+    name       VARCHAR(100) NOT NULL,
+    email      VARCHAR(100) NOT NULL,
+    message    TEXT         NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+) ENGINE = InnoDB;
+
+CREATE TABLE wallet_transactions
+(
+    id          BIGINT       NOT NULL AUTO_INCREMENT,
+    transfer_id VARCHAR(100) NOT NULL,
+    wallet_id   VARCHAR(100) NOT NULL,
+    type        VARCHAR(10)  NOT NULL,
+    amount      BIGINT       NOT NULL,
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_wallet_transactions_transfer_id (transfer_id),
+    INDEX idx_wallet_transactions_wallet_id (wallet_id),
+    CONSTRAINT fk_wallet_transactions_wallet FOREIGN KEY (wallet_id) REFERENCES wallets (id)
+) ENGINE = InnoDB;
+
+CREATE TABLE wallet_operations
+(
+    id              BIGINT       NOT NULL AUTO_INCREMENT,
+    idempotency_key VARCHAR(100) NOT NULL,
+    wallet_id       VARCHAR(100) NOT NULL,
+    type            VARCHAR(10)  NOT NULL,
+    amount          BIGINT       NOT NULL,
+    balance_after   BIGINT       NOT NULL,
+    created_at      TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_wallet_operations_idempotency_key (idempotency_key),
+    INDEX idx_wallet_operations_wallet_id (wallet_id),
+    CONSTRAINT fk_wallet_operations_wallet FOREIGN KEY (wallet_id) REFERENCES wallets (id)
+) ENGINE = InnoDB;
+
+ALTER TABLE wallets
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'IDR' AFTER balance,
+    ADD INDEX idx_wallets_user_id_currency (user_id, currency);
+
+ALTER TABLE wallet_transactions
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'IDR' AFTER amount;
+
+CREATE TABLE exchange_rates
+(
+    id             BIGINT          NOT NULL AUTO_INCREMENT,
+    base_currency  CHAR(3)         NOT NULL,
+    quote_currency CHAR(3)         NOT NULL,
+    rate           DECIMAL(24, 12) NOT NULL,
+    effective_at   TIMESTAMP       NOT NULL,
+    created_at     TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_exchange_rates_pair (base_currency, quote_currency, effective_at)
+) ENGINE = InnoDB;
+
+ALTER TABLE wallet_transactions
+    ADD INDEX idx_wallet_transactions_history (wallet_id, created_at);
+
+-- Give every existing wallet an opening movement so its history adds up to
+-- the current balance.
+INSERT INTO wallet_transactions (transfer_id, wallet_id, type, amount, currency, created_at)
+SELECT CONCAT('Opening-', opening.id), opening.id, 'opening', opening.amount, opening.currency, opening.created_at
+FROM (SELECT wallets.id,
+             wallets.currency,
+             wallets.created_at,
+             wallets.balance - COALESCE((SELECT SUM(CASE WHEN type = 'debit' THEN -amount ELSE amount END)
+                                         FROM wallet_transactions
+                                         WHERE wallet_id = wallets.id), 0) AS amount
+      FROM wallets) opening
+WHERE opening.amount <> 0;
+
+ALTER TABLE user_logs
+    ADD COLUMN entity_type VARCHAR(100) NULL AFTER action,
+    ADD COLUMN entity_id   VARCHAR(100) NULL AFTER entity_type,
+    ADD COLUMN payload     TEXT         NULL AFTER entity_id,
+    ADD INDEX idx_user_logs_entity (entity_type, entity_id);
+
+ALTER TABLE user_logs
+    MODIFY payload JSON NULL,
+    ADD INDEX idx_user_logs_user (user_id, created_at),
+    ADD INDEX idx_user_logs_created_at (created_at);
+
+CREATE TABLE user_log_archives
+(
+    id           BIGINT   NOT NULL AUTO_INCREMENT,
+    first_id     INT      NOT NULL,
+    last_id      INT      NOT NULL,
+    created_from BIGINT   NOT NULL,
+    created_to   BIGINT   NOT NULL,
+    entries      INT      NOT NULL,
+    data         LONGBLOB NOT NULL,
+    archived_at  BIGINT   NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_user_log_archives_created (created_from, created_to)
+) ENGINE = InnoDB;
+
+ALTER TABLE users
+    ADD COLUMN deleted_at TIMESTAMP(3) NULL AFTER updated_at,
+    ADD INDEX idx_users_deleted_at (deleted_at);
+
+ALTER TABLE wallets
+    ADD COLUMN deleted_at TIMESTAMP(3) NULL AFTER updated_at,
+    ADD INDEX idx_wallets_deleted_at (deleted_at);
+
+ALTER TABLE addresses
+    ADD COLUMN deleted_at TIMESTAMP(3) NULL AFTER updated_at,
+    ADD INDEX idx_addresses_deleted_at (deleted_at);
+
+ALTER TABLE products
+    ADD COLUMN deleted_at TIMESTAMP(3) NULL AFTER updated_at,
+    ADD INDEX idx_products_deleted_at (deleted_at);
+
+ALTER TABLE wallets
+    DROP INDEX idx_wallets_user_id_currency,
+    ADD UNIQUE INDEX idx_wallets_user_id_currency (user_id, currency);
@@ -0,0 +1,165 @@
+package golang_gorm
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"reflect"
+	"strings"
+)
+
+type DriftKind string
+
+const (
+	DriftMissingTable      DriftKind = "missing table"
+	DriftMissingColumn     DriftKind = "missing column"
+	DriftExtraColumn       DriftKind = "extra column"
+	DriftTypeMismatch      DriftKind = "type mismatch"
+	DriftMissingIndex      DriftKind = "missing index"
+	DriftMissingForeignKey DriftKind = "missing foreign key"
+)
+
+// Drift is one difference between a model and the table it maps to.
+type Drift struct {
+	Table    string
+	Kind     DriftKind
+	Name     string
+	Expected string
+	Actual   string
+}
+
+func (d Drift) String() string {
+	description := fmt.Sprintf("%s: %s %s", d.Table, d.Kind, d.Name)
+	if d.Expected != "" || d.Actual != "" {
+		description += fmt.Sprintf(" (expected %s, actual %s)", d.Expected, d.Actual)
+	}
+	return description
+}
+
+// databaseTypes lists, per GORM data type, the column types that can hold it
+// in MySQL and SQLite.
+var databaseTypes = map[schema.DataType][]string{
+	schema.Bool:   {"bool", "boolean", "tinyint", "numeric"},
+	schema.Int:    {"int", "integer", "bigint", "smallint", "tinyint", "mediumint"},
+	schema.Uint:   {"int", "integer", "bigint", "smallint", "tinyint", "mediumint"},
+	schema.Float:  {"float", "double", "decimal", "real", "numeric"},
+	schema.String: {"varchar", "char", "text", "tinytext", "mediumtext", "longtext"},
+	schema.Time:   {"datetime", "timestamp", "date"},
+	schema.Bytes:  {"blob", "tinyblob", "mediumblob", "longblob", "binary", "varbinary"},
+}
+
+// DetectDrift compares models, and the join tables of their many2many
+// relations, against the connected database. It looks for exactly what
+// AutoMigrate would add, plus columns the models no longer know about.
+func DetectDrift(db *gorm.DB, models ...interface{}) ([]Drift, error) {
+	var drifts []Drift
+	joinTables := map[string]*schema.Schema{}
+
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+
+		found, err := detectTableDrift(db, model, stmt.Schema)
+		if err != nil {
+			return nil, err
+		}
+		drifts = append(drifts, found...)
+
+		for _, relationship := range stmt.Schema.Relationships.Relations {
+			if relationship.JoinTable != nil {
+				joinTables[relationship.JoinTable.Table] = relationship.JoinTable
+			}
+		}
+	}
+
+	for _, joinTable := range joinTables {
+		joinValue := reflect.New(joinTable.ModelType).Interface()
+		found, err := detectTableDrift(db.Table(joinTable.Table), joinValue, joinTable)
+		if err != nil {
+			return nil, err
+		}
+		drifts = append(drifts, found...)
+	}
+
+	return drifts, nil
+}
+
+func detectTableDrift(db *gorm.DB, model interface{}, sch *schema.Schema) ([]Drift, error) {
+	migrator := db.Migrator()
+	if !migrator.HasTable(model) {
+		return []Drift{{Table: sch.Table, Kind: DriftMissingTable, Name: sch.Table}}, nil
+	}
+
+	columnTypes, err := migrator.ColumnTypes(model)
+	if err != nil {
+		return nil, err
+	}
+	actual := map[string]gorm.ColumnType{}
+	for _, columnType := range columnTypes {
+		actual[columnType.Name()] = columnType
+	}
+
+	var drifts []Drift
+	for _, dbName := range sch.DBNames {
+		field := sch.FieldsByDBName[dbName]
+		columnType, ok := actual[dbName]
+		if !ok {
+			drifts = append(drifts, Drift{Table: sch.Table, Kind: DriftMissingColumn, Name: dbName})
+			continue
+		}
+
+		databaseType := strings.ToLower(columnType.DatabaseTypeName())
+		if !compatibleType(field.DataType, databaseType) {
+			drifts = append(drifts, Drift{
+				Table:    sch.Table,
+				Kind:     DriftTypeMismatch,
+				Name:     dbName,
+				Expected: migrator.FullDataTypeOf(field).SQL,
+				Actual:   databaseType,
+			})
+		}
+	}
+
+	for _, columnType := range columnTypes {
+		if _, ok := sch.FieldsByDBName[columnType.Name()]; !ok {
+			drifts = append(drifts, Drift{Table: sch.Table, Kind: DriftExtraColumn, Name: columnType.Name()})
+		}
+	}
+
+	for _, index := range sch.ParseIndexes() {
+		if !migrator.HasIndex(model, index.Name) {
+			drifts = append(drifts, Drift{Table: sch.Table, Kind: DriftMissingIndex, Name: index.Name})
+		}
+	}
+
+	for _, relationship := range sch.Relationships.Relations {
+		if relationship.Field.IgnoreMigration {
+			continue
+		}
+		constraint := relationship.ParseConstraint()
+		if constraint == nil || constraint.Schema != sch {
+			continue
+		}
+		if !migrator.HasConstraint(model, constraint.Name) {
+			drifts = append(drifts, Drift{Table: sch.Table, Kind: DriftMissingForeignKey, Name: constraint.Name})
+		}
+	}
+
+	return drifts, nil
+}
+
+func compatibleType(dataType schema.DataType, databaseType string) bool {
+	candidates, ok := databaseTypes[dataType]
+	if !ok {
+		// Custom types declare their own column type; trust them.
+		return true
+	}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(databaseType, candidate) {
+			return true
+		}
+	}
+	return false
+}
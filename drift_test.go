@@ -0,0 +1,81 @@
+package golang_gorm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestSchemaDrift(t *testing.T) {
+	db := newTestDB(t)
+
+	drifts, err := DetectDrift(db, Models()...)
+	assert.Nil(t, err)
+	assert.Empty(t, drifts)
+}
+
+func TestSchemaDriftDetected(t *testing.T) {
+	db := newTestDB(t)
+
+	err := db.Migrator().DropColumn(&User{}, "last_name")
+	assert.Nil(t, err)
+	// SQLite drops a column by rebuilding the table, which loses its indexes.
+	if !db.Migrator().HasIndex(&User{}, "idx_users_deleted_at") {
+		err = db.Migrator().CreateIndex(&User{}, "idx_users_deleted_at")
+		assert.Nil(t, err)
+	}
+	err = db.Exec("alter table users add column nickname text").Error
+	assert.Nil(t, err)
+	err = db.Migrator().DropIndex(&Todo{}, "idx_todos_deleted_at")
+	assert.Nil(t, err)
+	err = db.Migrator().DropTable(&GuestBook{})
+	assert.Nil(t, err)
+	err = db.Exec("create table guest_books (id integer primary key, name text, email text, message integer, created_at datetime)").Error
+	assert.Nil(t, err)
+	err = db.Migrator().DropTable("user_like_product")
+	assert.Nil(t, err)
+
+	drifts, err := DetectDrift(db, Models()...)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []Drift{
+		{Table: "users", Kind: DriftMissingColumn, Name: "last_name"},
+		{Table: "users", Kind: DriftExtraColumn, Name: "nickname"},
+		{Table: "todos", Kind: DriftMissingIndex, Name: "idx_todos_deleted_at"},
+		{Table: "guest_books", Kind: DriftTypeMismatch, Name: "message", Expected: "text", Actual: "integer"},
+		{Table: "guest_books", Kind: DriftMissingColumn, Name: "updated_at"},
+		{Table: "user_like_product", Kind: DriftMissingTable, Name: "user_like_product"},
+	}, drifts)
+}
+
+func TestSchemaDriftMissingForeignKey(t *testing.T) {
+	db := newTestDB(t)
+
+	err := db.Migrator().DropTable(&Wallet{})
+	assert.Nil(t, err)
+	err = db.Exec("create table wallets (id text primary key, user_id text, balance integer, currency text, created_at datetime, updated_at datetime, deleted_at datetime)").Error
+	assert.Nil(t, err)
+	err = db.Exec("create index idx_wallets_deleted_at on wallets (deleted_at)").Error
+	assert.Nil(t, err)
+	err = db.Exec("create unique index idx_wallets_user_id_currency on wallets (user_id, currency)").Error
+	assert.Nil(t, err)
+
+	drifts, err := DetectDrift(db, Models()...)
+	assert.Nil(t, err)
+	assert.Equal(t, []Drift{{Table: "wallets", Kind: DriftMissingForeignKey, Name: "fk_users_wallet"}}, drifts)
+}
+
+func TestMigrationsMatchModels(t *testing.T) {
+	if os.Getenv("TEST_DB_DRIVER") != "mysql" {
+		t.Skip("the migrations are written for MySQL")
+	}
+	db := openMySQLTestDB(t)
+
+	runner, err := NewDefaultMigrationRunner(db)
+	assert.Nil(t, err)
+	_, err = runner.Up()
+	assert.Nil(t, err)
+
+	drifts, err := DetectDrift(db, Models()...)
+	assert.Nil(t, err)
+	assert.Empty(t, drifts)
+}
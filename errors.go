@@ -0,0 +1,142 @@
+package golang_gorm
+
+import (
+	"errors"
+	"fmt"
+	gomysql "github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"strings"
+)
+
+// Domain errors that Classify maps database failures to.
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrConflict    = errors.New("conflict")
+	ErrFKViolation = errors.New("foreign key violation")
+	ErrDataTooLong = errors.New("data too long")
+	ErrDeadlock    = errors.New("deadlock")
+	ErrLockTimeout = errors.New("lock wait timeout")
+)
+
+const (
+	mysqlDuplicateEntry   = 1062
+	mysqlNoReferencedRow  = 1216
+	mysqlRowIsReferenced  = 1217
+	mysqlDataTooLong      = 1406
+	mysqlRowIsReferenced2 = 1451
+	mysqlNoReferencedRow2 = 1452
+)
+
+// DBError is a classified database error. errors.Is matches it against both
+// Kind and the original error, so gorm.ErrRecordNotFound still matches too.
+type DBError struct {
+	Kind error
+	// Table, Constraint (or unique key), Column and Value name what the
+	// database complained about, as far as its message says.
+	Table      string
+	Constraint string
+	Column     string
+	Value      string
+	Err        error
+}
+
+func (e *DBError) Error() string {
+	var details []string
+	for _, detail := range []struct{ name, value string }{
+		{"table", e.Table}, {"constraint", e.Constraint}, {"column", e.Column}, {"value", e.Value},
+	} {
+		if detail.value != "" {
+			details = append(details, fmt.Sprintf("%s %s", detail.name, detail.value))
+		}
+	}
+	if len(details) == 0 {
+		return fmt.Sprintf("%s: %s", e.Kind, e.Err)
+	}
+	return fmt.Sprintf("%s (%s): %s", e.Kind, strings.Join(details, ", "), e.Err)
+}
+
+func (e *DBError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+var (
+	// Duplicate entry 'x' for key 'users.PRIMARY'
+	mysqlDuplicatePattern = regexp.MustCompile(`Duplicate entry '(.*)' for key '(?:([^'.]+)\.)?([^']+)'`)
+	// ... foreign key constraint fails (`db`.`wallets`, CONSTRAINT `fk_users_wallet` FOREIGN KEY (`user_id`) ...
+	mysqlForeignKeyPattern = regexp.MustCompile("`([^`]+)`, CONSTRAINT `([^`]+)` FOREIGN KEY \\(`([^`]+)`\\)")
+	// Data too long for column 'name' at row 1
+	mysqlTooLongPattern = regexp.MustCompile(`Data too long for column '([^']+)'`)
+	// UNIQUE constraint failed: users.id
+	sqliteUniquePattern = regexp.MustCompile(`UNIQUE constraint failed: (\w+)\.(\w+)`)
+)
+
+// Classify maps err to a *DBError carrying one of the domain errors. Errors it
+// does not recognise, and nil, are returned unchanged.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	var classified *DBError
+	if errors.As(err, &classified) {
+		return err
+	}
+
+	dbErr := &DBError{Err: err}
+	var mysqlErr *gomysql.MySQLError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		dbErr.Kind = ErrNotFound
+	case errors.As(err, &mysqlErr):
+		classifyMySQL(dbErr, mysqlErr)
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		dbErr.Kind = ErrConflict
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		dbErr.Kind = ErrFKViolation
+	default:
+		classifySQLite(dbErr, err.Error())
+	}
+
+	if dbErr.Kind == nil {
+		return err
+	}
+	return dbErr
+}
+
+func classifyMySQL(dbErr *DBError, err *gomysql.MySQLError) {
+	switch err.Number {
+	case mysqlDuplicateEntry:
+		dbErr.Kind = ErrConflict
+		if match := mysqlDuplicatePattern.FindStringSubmatch(err.Message); match != nil {
+			dbErr.Value, dbErr.Table, dbErr.Constraint = match[1], match[2], match[3]
+		}
+	case mysqlNoReferencedRow, mysqlRowIsReferenced, mysqlRowIsReferenced2, mysqlNoReferencedRow2:
+		dbErr.Kind = ErrFKViolation
+		if match := mysqlForeignKeyPattern.FindStringSubmatch(err.Message); match != nil {
+			dbErr.Table, dbErr.Constraint, dbErr.Column = match[1], match[2], match[3]
+		}
+	case mysqlDataTooLong:
+		dbErr.Kind = ErrDataTooLong
+		if match := mysqlTooLongPattern.FindStringSubmatch(err.Message); match != nil {
+			dbErr.Column = match[1]
+		}
+	case mysqlDeadlock:
+		dbErr.Kind = ErrDeadlock
+	case mysqlLockWaitTimeout:
+		dbErr.Kind = ErrLockTimeout
+	}
+}
+
+// classifySQLite reads the messages of the SQLite driver used by the tests,
+// which has no exported error numbers worth depending on.
+func classifySQLite(dbErr *DBError, message string) {
+	switch {
+	case strings.Contains(message, "UNIQUE constraint failed"):
+		dbErr.Kind = ErrConflict
+		if match := sqliteUniquePattern.FindStringSubmatch(message); match != nil {
+			dbErr.Table, dbErr.Column = match[1], match[2]
+		}
+	case strings.Contains(message, "FOREIGN KEY constraint failed"):
+		dbErr.Kind = ErrFKViolation
+	}
+}
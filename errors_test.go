@@ -0,0 +1,85 @@
+package golang_gorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	gomysql "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestClassifyMySQL(t *testing.T) {
+	err := Classify(fmt.Errorf("create user: %w", &gomysql.MySQLError{
+		Number:  1062,
+		Message: "Duplicate entry 'User-01' for key 'users.PRIMARY'",
+	}))
+	assert.True(t, errors.Is(err, ErrConflict))
+	var dbErr *DBError
+	assert.True(t, errors.As(err, &dbErr))
+	assert.Equal(t, "users", dbErr.Table)
+	assert.Equal(t, "PRIMARY", dbErr.Constraint)
+	assert.Equal(t, "User-01", dbErr.Value)
+	assert.Equal(t, "conflict (table users, constraint PRIMARY, value User-01): create user: Error 1062: Duplicate entry 'User-01' for key 'users.PRIMARY'", err.Error())
+
+	err = Classify(&gomysql.MySQLError{
+		Number:  1452,
+		Message: "Cannot add or update a child row: a foreign key constraint fails (`golang_gorm`.`wallets`, CONSTRAINT `fk_users_wallet` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))",
+	})
+	assert.True(t, errors.Is(err, ErrFKViolation))
+	assert.True(t, errors.As(err, &dbErr))
+	assert.Equal(t, "wallets", dbErr.Table)
+	assert.Equal(t, "fk_users_wallet", dbErr.Constraint)
+	assert.Equal(t, "user_id", dbErr.Column)
+
+	err = Classify(&gomysql.MySQLError{
+		Number:  1451,
+		Message: "Cannot delete or update a parent row: a foreign key constraint fails (`golang_gorm`.`addresses`, CONSTRAINT `fk_users_addresses` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))",
+	})
+	assert.True(t, errors.Is(err, ErrFKViolation))
+
+	err = Classify(&gomysql.MySQLError{Number: 1406, Message: "Data too long for column 'first_name' at row 1"})
+	assert.True(t, errors.Is(err, ErrDataTooLong))
+	assert.True(t, errors.As(err, &dbErr))
+	assert.Equal(t, "first_name", dbErr.Column)
+
+	assert.True(t, errors.Is(Classify(deadlock), ErrDeadlock))
+	assert.True(t, errors.Is(Classify(lockWaitTimeout), ErrLockTimeout))
+	assert.True(t, IsRetryable(Classify(deadlock)))
+}
+
+func TestClassify(t *testing.T) {
+	assert.Nil(t, Classify(nil))
+
+	err := Classify(gorm.ErrRecordNotFound)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	assert.Equal(t, err, Classify(err))
+
+	assert.True(t, errors.Is(Classify(gorm.ErrDuplicatedKey), ErrConflict))
+	assert.True(t, errors.Is(Classify(gorm.ErrForeignKeyViolated), ErrFKViolation))
+
+	unknown := errors.New("connection refused")
+	assert.Equal(t, unknown, Classify(unknown))
+}
+
+func TestClassifyDatabase(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+	ctx := context.Background()
+
+	err := Classify(db.Create(&User{ID: "1", Password: "rahasia"}).Error)
+	assert.True(t, errors.Is(err, ErrConflict))
+	var dbErr *DBError
+	assert.True(t, errors.As(err, &dbErr))
+	assert.Equal(t, "users", dbErr.Table)
+	assert.Equal(t, "id", dbErr.Column)
+
+	err = NewRepository[Wallet, string](db).Create(ctx, &Wallet{UserId: "missing"})
+	assert.True(t, errors.Is(err, ErrFKViolation))
+
+	// A soft delete cascades; removing the user for good leaves its wallet
+	// to the foreign key.
+	err = Classify(db.Unscoped().Delete(&User{}, "id = ?", "1").Error)
+	assert.True(t, errors.Is(err, ErrFKViolation))
+}
@@ -0,0 +1,18 @@
+package golang_gorm
+
+import "time"
+
+// ExchangeRate says how many units of QuoteCurrency one unit of BaseCurrency
+// buys from EffectiveAt on. Rate is a decimal string such as "0.000064".
+type ExchangeRate struct {
+	ID            int64     `gorm:"primary_key;column:id;autoIncrement"`
+	BaseCurrency  string    `gorm:"column:base_currency;size:3;index:idx_exchange_rates_pair"`
+	QuoteCurrency string    `gorm:"column:quote_currency;size:3;index:idx_exchange_rates_pair"`
+	Rate          string    `gorm:"column:rate;type:decimal(24,12)"`
+	EffectiveAt   time.Time `gorm:"column:effective_at;index:idx_exchange_rates_pair"`
+	CreatedAt     time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (e *ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
@@ -0,0 +1,178 @@
+package golang_gorm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"io/fs"
+	"path"
+	"reflect"
+)
+
+// FixtureLoader inserts rows described in YAML or JSON files. Every file holds
+// a list of rows keyed by column name and is named after the table it fills,
+// e.g. users.yaml or user_like_product.json.
+//
+// Rows for tables that belong to one of Models are decoded into the model and
+// created through it, so hooks and defaults apply just like in application
+// code. Rows for other tables (join tables, sample) are inserted as maps.
+type FixtureLoader struct {
+	db     *gorm.DB
+	fsys   fs.FS
+	models map[string]*schema.Schema
+}
+
+var fixtureExtensions = []string{".yaml", ".yml", ".json"}
+
+func NewFixtureLoader(db *gorm.DB, fsys fs.FS) (*FixtureLoader, error) {
+	models := map[string]*schema.Schema{}
+	for _, model := range Models() {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+		models[stmt.Schema.Table] = stmt.Schema
+	}
+
+	return &FixtureLoader{db: db, fsys: fsys, models: models}, nil
+}
+
+// Load inserts the named fixtures in the given order inside one transaction.
+func (l *FixtureLoader) Load(names ...string) error {
+	return l.db.Transaction(func(tx *gorm.DB) error {
+		for _, name := range names {
+			rows, err := l.read(name)
+			if err != nil {
+				return err
+			}
+			if err := l.insert(tx, name, rows); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Reset empties the tables of the named fixtures, children first, and loads
+// them again so every caller starts from the same rows. Rows of other tables
+// that point at the emptied ones, such as wallet_transactions for wallets, are
+// deleted too since their parents are gone.
+func (l *FixtureLoader) Reset(names ...string) error {
+	tables := append(append([]string{}, names...), l.dependants(names)...)
+	err := l.db.Transaction(func(tx *gorm.DB) error {
+		for i := len(tables) - 1; i >= 0; i-- {
+			err := tx.Exec("DELETE FROM ?", clause.Table{Name: tables[i]}).Error
+			if err != nil {
+				return fmt.Errorf("fixture %s: %w", tables[i], err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return l.Load(names...)
+}
+
+// dependants returns the tables outside names that reference one of them,
+// directly or through each other, parents before children.
+func (l *FixtureLoader) dependants(names []string) []string {
+	seen := map[string]bool{}
+	for _, name := range names {
+		seen[name] = true
+	}
+
+	var dependants []string
+	for found := true; found; {
+		found = false
+		for _, sch := range l.models {
+			for _, relationship := range sch.Relationships.Relations {
+				table := ""
+				switch {
+				case relationship.JoinTable != nil:
+					if seen[sch.Table] || seen[relationship.FieldSchema.Table] {
+						table = relationship.JoinTable.Table
+					}
+				case relationship.Type == schema.BelongsTo:
+					if seen[relationship.FieldSchema.Table] {
+						table = sch.Table
+					}
+				default:
+					if seen[sch.Table] {
+						table = relationship.FieldSchema.Table
+					}
+				}
+				if table != "" && !seen[table] {
+					seen[table] = true
+					dependants = append(dependants, table)
+					found = true
+				}
+			}
+		}
+	}
+	return dependants
+}
+
+func (l *FixtureLoader) read(name string) ([]map[string]interface{}, error) {
+	for _, extension := range fixtureExtensions {
+		content, err := fs.ReadFile(l.fsys, name+extension)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var rows []map[string]interface{}
+		if path.Ext(name+extension) == ".json" {
+			err = json.Unmarshal(content, &rows)
+		} else {
+			err = yaml.Unmarshal(content, &rows)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("fixture %s: %w", name, err)
+		}
+		return rows, nil
+	}
+
+	return nil, fmt.Errorf("fixture %s: %w", name, fs.ErrNotExist)
+}
+
+func (l *FixtureLoader) insert(tx *gorm.DB, name string, rows []map[string]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	sch, ok := l.models[name]
+	if !ok {
+		if err := tx.Table(name).Create(&rows).Error; err != nil {
+			return fmt.Errorf("fixture %s: %w", name, err)
+		}
+		return nil
+	}
+
+	for i, row := range rows {
+		model := reflect.New(sch.ModelType)
+		for column, value := range row {
+			field := sch.LookUpField(column)
+			if field == nil || field.DBName == "" {
+				return fmt.Errorf("fixture %s row %d: unknown column %s", name, i, column)
+			}
+			if err := field.Set(context.Background(), model.Elem(), value); err != nil {
+				return fmt.Errorf("fixture %s row %d column %s: %w", name, i, column, err)
+			}
+		}
+
+		if err := tx.Omit(clause.Associations).Create(model.Interface()).Error; err != nil {
+			return fmt.Errorf("fixture %s row %d: %w", name, i, err)
+		}
+	}
+
+	return nil
+}
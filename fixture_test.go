@@ -0,0 +1,66 @@
+package golang_gorm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFixtureLoad(t *testing.T) {
+	db := newTestDB(t, "users", "todos", "sample")
+
+	var todo Todo
+	err := db.Unscoped().Take(&todo, "id = ?", 3).Error
+	assert.Nil(t, err)
+	assert.True(t, todo.DeletedAt.Valid)
+
+	var count int64
+	err = db.Model(&Todo{}).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), count)
+
+	err = db.Table("sample").Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), count)
+}
+
+func TestFixtureReset(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+
+	err := db.Create(&User{ID: "50", Name: Name{FirstName: "User 50"}}).Error
+	assert.Nil(t, err)
+	err = db.Model(&Wallet{}).Where("id = ?", "1").Update("balance", 0).Error
+	assert.Nil(t, err)
+
+	loader, err := NewFixtureLoader(db, os.DirFS("testdata/fixtures"))
+	assert.Nil(t, err)
+	err = loader.Reset("users", "wallets")
+	assert.Nil(t, err)
+
+	var count int64
+	err = db.Model(&User{}).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(19), count)
+
+	var wallet Wallet
+	err = db.Take(&wallet, "id = ?", "1").Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1000000000), wallet.Balance)
+}
+
+func TestFixtureUnknownColumn(t *testing.T) {
+	db := newTestDB(t)
+
+	loader, err := NewFixtureLoader(db, fstest.MapFS{
+		"users.yaml": {Data: []byte("- id: \"1\"\n  nickname: brian\n")},
+	})
+	assert.Nil(t, err)
+
+	err = loader.Load("users")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unknown column nickname")
+
+	err = loader.Load("wallets")
+	assert.NotNil(t, err)
+}
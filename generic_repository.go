@@ -0,0 +1,236 @@
+package golang_gorm
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var ErrSoftDeleteUnsupported = errors.New("model does not support soft delete")
+
+// Scope narrows a query, like the scopes passed to gorm's Scopes.
+type Scope func(db *gorm.DB) *gorm.DB
+
+// Where is a Scope for a single condition.
+func Where(query interface{}, args ...interface{}) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(query, args...)
+	}
+}
+
+// OrderBy is a Scope that sorts the results.
+func OrderBy(order string) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Order(order)
+	}
+}
+
+// Repository is the CRUD every model shares, addressed by the model's primary
+// key of type ID. Models with a gorm.DeletedAt field are soft deleted. Errors
+// go through Classify, so callers can test them against ErrNotFound,
+// ErrConflict and the other domain errors.
+type Repository[T any, ID comparable] struct {
+	db *gorm.DB
+}
+
+func NewRepository[T any, ID comparable](db *gorm.DB) *Repository[T, ID] {
+	return &Repository[T, ID]{db: db}
+}
+
+func (r *Repository[T, ID]) FindByID(ctx context.Context, id ID) (T, error) {
+	var entity T
+	err := FromContext(ctx, r.db).Where(clause.Eq{Column: clause.PrimaryColumn, Value: id}).Take(&entity).Error
+	return entity, Classify(err)
+}
+
+// FindByIDs returns the rows that exist among ids, in primary key order.
+func (r *Repository[T, ID]) FindByIDs(ctx context.Context, ids []ID) ([]T, error) {
+	var entities []T
+	err := FromContext(ctx, r.db).Where(clause.IN{Column: clause.PrimaryColumn, Values: values(ids)}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: clause.PrimaryKey}}).Find(&entities).Error
+	return entities, Classify(err)
+}
+
+func (r *Repository[T, ID]) FindAll(ctx context.Context, scopes ...Scope) ([]T, error) {
+	var entities []T
+	err := r.query(ctx, scopes).Find(&entities).Error
+	return entities, Classify(err)
+}
+
+func (r *Repository[T, ID]) Count(ctx context.Context, scopes ...Scope) (int64, error) {
+	var count int64
+	err := r.query(ctx, scopes).Model(new(T)).Count(&count).Error
+	return count, Classify(err)
+}
+
+// Paginate returns the requested page of matching rows and their total.
+func (r *Repository[T, ID]) Paginate(ctx context.Context, request PageRequest, scopes ...Scope) (Page[T], error) {
+	return FindPage[T](ctx, r.db, request, scopes...)
+}
+
+// FindKeyset returns one page of matching rows after or before the keyset's
+// cursor.
+func (r *Repository[T, ID]) FindKeyset(ctx context.Context, keyset Keyset, scopes ...Scope) (CursorPage[T], error) {
+	return FindKeyset[T](ctx, r.db, keyset, scopes...)
+}
+
+// FindInBatches calls fn with successive batches of matching rows until fn
+// returns an error or the rows run out.
+func (r *Repository[T, ID]) FindInBatches(ctx context.Context, size int, fn func(batch []T) error, scopes ...Scope) error {
+	var batch []T
+	err := r.query(ctx, scopes).FindInBatches(&batch, size, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}).Error
+	return Classify(err)
+}
+
+// Create inserts the entity. Like Save, it leaves associations alone.
+func (r *Repository[T, ID]) Create(ctx context.Context, entity *T) error {
+	return Classify(FromContext(ctx, r.db).Omit(clause.Associations).Create(entity).Error)
+}
+
+func (r *Repository[T, ID]) CreateInBatches(ctx context.Context, entities []T, size int) error {
+	return Classify(FromContext(ctx, r.db).Omit(clause.Associations).CreateInBatches(entities, size).Error)
+}
+
+// Save writes every column of the existing row with the entity's primary key,
+// and reports gorm.ErrRecordNotFound when there is none or it is soft deleted;
+// unlike gorm's Save it never inserts. Associations are left alone; save them
+// through their own repository.
+func (r *Repository[T, ID]) Save(ctx context.Context, entity *T) error {
+	tx := FromContext(ctx, r.db)
+	result := tx.Model(entity).Select("*").Omit(clause.Associations).Updates(entity)
+	if result.Error != nil || result.RowsAffected > 0 {
+		return Classify(result.Error)
+	}
+	// MySQL counts changed rows only, so a row saved as it was looks the
+	// same as a missing one.
+	stmt := result.Statement
+	id, _ := stmt.Schema.PrioritizedPrimaryField.ValueOf(stmt.Context, stmt.ReflectValue)
+	var count int64
+	err := tx.Model(new(T)).Where(clause.Eq{Column: clause.PrimaryColumn, Value: id}).Count(&count).Error
+	if err != nil {
+		return Classify(err)
+	}
+	if count == 0 {
+		return Classify(gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// Updates changes the given columns, a map or a struct of non-zero fields, of
+// one row.
+func (r *Repository[T, ID]) Updates(ctx context.Context, id ID, values interface{}) error {
+	result := FromContext(ctx, r.db).Model(new(T)).
+		Where(clause.Eq{Column: clause.PrimaryColumn, Value: id}).Updates(values)
+	return rowsAffected(result)
+}
+
+// Upsert inserts the entities, updating rows whose primary key already
+// exists. Only the named columns are updated, or if none are given every
+// column but the primary key, created_at and deleted_at: a soft deleted row
+// is updated but stays deleted.
+func (r *Repository[T, ID]) Upsert(ctx context.Context, entities []T, columns ...string) error {
+	if len(entities) == 0 {
+		return nil
+	}
+	tx := FromContext(ctx, r.db)
+	if len(columns) == 0 {
+		sch, err := parseSchema(tx, new(T))
+		if err != nil {
+			return err
+		}
+		columns = upsertColumns(sch)
+	}
+	onConflict := clause.OnConflict{DoUpdates: clause.AssignmentColumns(columns)}
+	return Classify(tx.Omit(clause.Associations).Clauses(onConflict).Create(&entities).Error)
+}
+
+// Delete removes one row, softly if the model supports it, and reports
+// gorm.ErrRecordNotFound when there was none.
+func (r *Repository[T, ID]) Delete(ctx context.Context, id ID) error {
+	return rowsAffected(FromContext(ctx, r.db).Delete(new(T), clause.Eq{Column: clause.PrimaryColumn, Value: id}))
+}
+
+// DeleteByIDs removes every listed row and returns how many there were.
+func (r *Repository[T, ID]) DeleteByIDs(ctx context.Context, ids []ID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := FromContext(ctx, r.db).Delete(new(T), clause.IN{Column: clause.PrimaryColumn, Values: values(ids)})
+	return result.RowsAffected, Classify(result.Error)
+}
+
+// SoftDelete is Delete for models that can be restored afterwards.
+func (r *Repository[T, ID]) SoftDelete(ctx context.Context, id ID) error {
+	if err := r.requireSoftDelete(); err != nil {
+		return err
+	}
+	return r.Delete(ctx, id)
+}
+
+// Restore brings back a soft deleted row and the children deleted with it;
+// see RestoreDeleted.
+func (r *Repository[T, ID]) Restore(ctx context.Context, id ID) error {
+	restored, err := RestoreDeleted(ctx, r.db, new(T), id)
+	if err != nil {
+		return err
+	}
+	if restored == 0 {
+		return Classify(gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func (r *Repository[T, ID]) query(ctx context.Context, scopes []Scope) *gorm.DB {
+	db := FromContext(ctx, r.db)
+	for _, scope := range scopes {
+		db = db.Scopes(scope)
+	}
+	return db
+}
+
+func (r *Repository[T, ID]) requireSoftDelete() error {
+	stmt := &gorm.Statement{DB: r.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return err
+	}
+	if softDeleteField(stmt.Schema) == nil {
+		return ErrSoftDeleteUnsupported
+	}
+	return nil
+}
+
+// upsertColumns lists the columns Upsert updates by default.
+func upsertColumns(sch *schema.Schema) []string {
+	var columns []string
+	for _, dbName := range sch.DBNames {
+		field := sch.FieldsByDBName[dbName]
+		if field.PrimaryKey || !field.Updatable || field == softDeleteField(sch) ||
+			(field.AutoCreateTime > 0 && field.AutoUpdateTime == 0) {
+			continue
+		}
+		columns = append(columns, dbName)
+	}
+	return columns
+}
+
+func rowsAffected(result *gorm.DB) error {
+	if result.Error != nil {
+		return Classify(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return Classify(gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func values[ID any](ids []ID) []interface{} {
+	values := make([]interface{}, len(ids))
+	for i, id := range ids {
+		values[i] = id
+	}
+	return values
+}
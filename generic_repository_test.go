@@ -0,0 +1,165 @@
+package golang_gorm
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestGenericRepositoryFind(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+	users := NewRepository[User, string](db)
+	ctx := context.Background()
+
+	user, err := users.FindByID(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, "Brian", user.Name.FirstName)
+
+	found, err := users.FindByIDs(ctx, []string{"3", "2", "missing"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(found))
+	assert.Equal(t, "2", found[0].ID)
+
+	found, err = users.FindAll(ctx, Where("first_name LIKE ?", "User%"), OrderBy("id desc"))
+	assert.Nil(t, err)
+	assert.Equal(t, 18, len(found))
+	assert.Equal(t, "9", found[0].ID)
+
+	count, err := users.Count(ctx, Where("first_name = ?", "User"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(8), count)
+
+	page, err := users.Paginate(ctx, PageRequest{Page: 2, Size: 5}, OrderBy("id asc"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(19), page.TotalItems)
+	assert.Equal(t, 5, len(page.Items))
+	assert.Equal(t, "17", page.Items[0].ID)
+
+	var seen int
+	err = users.FindInBatches(ctx, 7, func(batch []User) error {
+		seen += len(batch)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 19, seen)
+
+	wallets := NewRepository[Wallet, string](db)
+	rich, err := wallets.FindAll(ctx, SultanWalletBalance)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(rich))
+}
+
+func TestGenericRepositoryWrite(t *testing.T) {
+	db := newTestDB(t, "products")
+	products := NewRepository[Product, string](db)
+	ctx := context.Background()
+
+	err := products.CreateInBatches(ctx, []Product{
+		{ID: "P003", Name: "Product 3", Price: 300},
+		{ID: "P004", Name: "Product 4", Price: 400},
+		{ID: "P005", Name: "Product 5", Price: 500},
+	}, 2)
+	assert.Nil(t, err)
+
+	err = products.Updates(ctx, "P003", map[string]interface{}{"price": 350})
+	assert.Nil(t, err)
+	err = products.Updates(ctx, "missing", map[string]interface{}{"price": 1})
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	err = products.Upsert(ctx, []Product{
+		{ID: "P003", Name: "Renamed", Price: 1},
+		{ID: "P006", Name: "Product 6", Price: 600},
+	}, "name")
+	assert.Nil(t, err)
+	product, err := products.FindByID(ctx, "P003")
+	assert.Nil(t, err)
+	assert.Equal(t, "Renamed", product.Name)
+	assert.Equal(t, int64(350), product.Price)
+
+	created, err := products.FindByID(ctx, "P004")
+	assert.Nil(t, err)
+	err = products.Upsert(ctx, []Product{{ID: "P004", Name: "Everything", Price: 1, CreatedAt: created.CreatedAt.Add(time.Hour)}})
+	assert.Nil(t, err)
+	product, err = products.FindByID(ctx, "P004")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), product.Price)
+	assert.True(t, created.CreatedAt.Equal(product.CreatedAt))
+
+	deleted, err := products.DeleteByIDs(ctx, []string{"P003", "P004", "missing"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	err = products.Delete(ctx, "P005")
+	assert.Nil(t, err)
+	err = products.Delete(ctx, "P005")
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	// Upserting over a soft deleted row updates it but leaves it deleted.
+	err = products.Upsert(ctx, []Product{{ID: "P005", Name: "Product 5", Price: 1}})
+	assert.Nil(t, err)
+	_, err = products.FindByID(ctx, "P005")
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	found, err := products.FindAll(ctx, WithDeleted(), Where("id = ?", "P005"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), found[0].Price)
+	assert.True(t, found[0].DeletedAt.Valid)
+
+	count, err := products.Count(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), count)
+
+	err = NewRepository[GuestBook, int64](db).SoftDelete(ctx, 1)
+	assert.Equal(t, ErrSoftDeleteUnsupported, err)
+}
+
+func TestGenericRepositoryCreateLeavesAssociations(t *testing.T) {
+	db := newTestDB(t, "users")
+	ctx := context.Background()
+
+	err := NewRepository[Product, string](db).Create(ctx, &Product{
+		ID: "P010", Name: "Product 10", LikedByUsers: []User{{ID: "1"}, {ID: "70"}},
+	})
+	assert.Nil(t, err)
+
+	var likes int64
+	err = db.Table("user_like_product").Where("product_id = ?", "P010").Count(&likes).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), likes)
+	err = db.Take(&User{}, "id = ?", "70").Error
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	err = NewRepository[Wallet, string](db).Create(ctx, &Wallet{ID: "70", UserId: "1", User: &User{ID: "1", Name: Name{FirstName: "Changed"}}})
+	assert.Nil(t, err)
+	var user User
+	err = db.Take(&user, "id = ?", "1").Error
+	assert.Nil(t, err)
+	assert.NotEqual(t, "Changed", user.Name.FirstName)
+}
+
+func TestGenericRepositorySoftDelete(t *testing.T) {
+	db := newTestDB(t, "users", "todos")
+	todos := NewRepository[Todo, uint](db)
+	ctx := context.Background()
+
+	err := todos.SoftDelete(ctx, 1)
+	assert.Nil(t, err)
+	_, err = todos.FindByID(ctx, 1)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	var todo Todo
+	err = db.Unscoped().Take(&todo, "id = ?", 1).Error
+	assert.Nil(t, err)
+	assert.True(t, todo.DeletedAt.Valid)
+
+	err = todos.Restore(ctx, 1)
+	assert.Nil(t, err)
+	err = todos.Restore(ctx, 1)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	todo, err = todos.FindByID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "Todo 1", todo.Description)
+}
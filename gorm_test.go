@@ -3,47 +3,24 @@ package golang_gorm
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
-	"gorm.io/gorm/logger"
 	"strconv"
 	"testing"
-	"time"
 )
 
-func OpenConnection() *gorm.DB {
-	dialect := mysql.Open("root:123@tcp(localhost:3306)/golang_gorm?charset=utf8mb4&parseTime=True&loc=Local")
-	db, err := gorm.Open(dialect, &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
-	})
-	if err != nil {
-		panic(err)
-	}
-
-	sqlDB, err := db.DB()
-	if err != nil {
-		panic(err)
-	}
-
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetConnMaxLifetime(30 * time.Minute)
-	sqlDB.SetConnMaxIdleTime(5 * time.Minute)
-
-	return db
-
-}
-
-var db = OpenConnection()
-
 func TestOpenConnection(t *testing.T) {
+	db := newTestDB(t)
+
 	assert.NotNil(t, db)
 }
 
 func TestExecuteSQL(t *testing.T) {
+	db := newTestDB(t)
+
 	err := db.Exec("insert into sample(id, name) values(?, ?)", "1", "Brian").Error
 	assert.Nil(t, err)
 
@@ -66,6 +43,8 @@ type Sample struct {
 }
 
 func TestQuerySQL(t *testing.T) {
+	db := newTestDB(t, "sample")
+
 	var sample Sample
 	err := db.Raw("select id, name from sample where id = ?", "1").Scan(&sample).Error
 	assert.Nil(t, err)
@@ -77,6 +56,8 @@ func TestQuerySQL(t *testing.T) {
 }
 
 func TestSqlRow(t *testing.T) {
+	db := newTestDB(t, "sample")
+
 	rows, err := db.Raw("select id, name from sample where id = ?", "1").Rows()
 	assert.Nil(t, err)
 	defer func(rows *sql.Rows) {
@@ -97,6 +78,8 @@ func TestSqlRow(t *testing.T) {
 }
 
 func TestScanRow(t *testing.T) {
+	db := newTestDB(t, "sample")
+
 	rows, err := db.Raw("select id, name from sample").Rows()
 	assert.Nil(t, err)
 	defer func(rows *sql.Rows) {
@@ -113,6 +96,8 @@ func TestScanRow(t *testing.T) {
 }
 
 func TestCreateUsers(t *testing.T) {
+	db := newTestDB(t)
+
 	user := User{
 		ID:       "1",
 		Password: "rahasia",
@@ -129,6 +114,8 @@ func TestCreateUsers(t *testing.T) {
 }
 
 func TestBatchInsert(t *testing.T) {
+	db := newTestDB(t)
+
 	var users []User
 	for i := 2; i < 10; i++ {
 		user := User{
@@ -148,18 +135,20 @@ func TestBatchInsert(t *testing.T) {
 }
 
 func TestTransactionSuccess(t *testing.T) {
+	db := newTestDB(t)
+
 	err := db.Transaction(func(tx *gorm.DB) error {
-		err := db.Create(&User{ID: "11", Password: "rahasia", Name: Name{FirstName: "User 11"}}).Error
+		err := tx.Create(&User{ID: "11", Password: "rahasia", Name: Name{FirstName: "User 11"}}).Error
 		if err != nil {
 			return err
 		}
 
-		err = db.Create(&User{ID: "12", Password: "rahasia", Name: Name{FirstName: "User 12"}}).Error
+		err = tx.Create(&User{ID: "12", Password: "rahasia", Name: Name{FirstName: "User 12"}}).Error
 		if err != nil {
 			return err
 		}
 
-		err = db.Create(&User{ID: "13", Password: "rahasia", Name: Name{FirstName: "User 13"}}).Error
+		err = tx.Create(&User{ID: "13", Password: "rahasia", Name: Name{FirstName: "User 13"}}).Error
 		if err != nil {
 			return err
 		}
@@ -171,6 +160,8 @@ func TestTransactionSuccess(t *testing.T) {
 }
 
 func TestTransactionRollback(t *testing.T) {
+	db := newTestDB(t, "users")
+
 	err := db.Transaction(func(tx *gorm.DB) error {
 		err := tx.Create(&User{ID: "16", Password: "rahasia", Name: Name{FirstName: "User 16"}}).Error
 		if err != nil {
@@ -185,10 +176,12 @@ func TestTransactionRollback(t *testing.T) {
 		return nil
 	})
 
-	assert.NotNil(t, err)
+	assert.True(t, errors.Is(Classify(err), ErrConflict))
 }
 
 func TestManualTransactionSuccess(t *testing.T) {
+	db := newTestDB(t)
+
 	tx := db.Begin()
 	defer tx.Rollback()
 
@@ -204,6 +197,8 @@ func TestManualTransactionSuccess(t *testing.T) {
 }
 
 func TestManualTransactionRollback(t *testing.T) {
+	db := newTestDB(t, "users")
+
 	tx := db.Begin()
 	defer tx.Rollback()
 
@@ -229,6 +224,8 @@ func TestManualTransactionRollback(t *testing.T) {
 }
 
 func TestQuerySingleObject(t *testing.T) {
+	db := newTestDB(t, "users")
+
 	user := User{}
 	err := db.First(&user).Error
 	assert.Nil(t, err)
@@ -241,6 +238,8 @@ func TestQuerySingleObject(t *testing.T) {
 }
 
 func TestQuerySingleObjectInlineCondition(t *testing.T) {
+	db := newTestDB(t, "users")
+
 	user := User{}
 	err := db.First(&user, "id = ?", "5").Error
 	assert.Nil(t, err)
@@ -253,6 +252,8 @@ func TestQuerySingleObjectInlineCondition(t *testing.T) {
 }
 
 func TestQueryAllObject(t *testing.T) {
+	db := newTestDB(t, "users")
+
 	var users []User
 	err := db.Find(&users, "id in ?", []string{"1", "2"}).Error
 	assert.Nil(t, err)
@@ -260,6 +261,8 @@ func TestQueryAllObject(t *testing.T) {
 }
 
 func TestQueryWhere(t *testing.T) {
+	db := newTestDB(t, "users")
+
 	var user User
 	err := db.Where("first_name like ?", "%User%").Where("id = ?", "5").Find(&user).Error
 	assert.Nil(t, err)
@@ -267,20 +270,26 @@ func TestQueryWhere(t *testing.T) {
 }
 
 func TestQueryOr(t *testing.T) {
+	db := newTestDB(t, "users")
+
 	var users []User
-	err := db.Where("first_name like ?", "%User%").Or("password = ?", "rahasia").Find(&users).Error
+	err := db.Where("first_name like ?", "%User%").Or("last_name = ?", "Anashari").Find(&users).Error
 	assert.Nil(t, err)
-	assert.Equal(t, 18, len(users))
+	assert.Equal(t, 19, len(users))
 }
 
 func TestQueryNot(t *testing.T) {
+	db := newTestDB(t, "users")
+
 	var users []User
-	err := db.Not("first_name like ?", "%User%").Where("password = ?", "rahasia").Find(&users).Error
+	err := db.Not("first_name like ?", "%User%").Where("last_name = ?", "Anashari").Find(&users).Error
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(users))
 }
 
 func TestSelectFields(t *testing.T) {
+	db := newTestDB(t, "users")
+
 	var users []User
 	err := db.Select("id, first_name").Find(&users).Error
 	assert.Nil(t, err)
@@ -290,10 +299,12 @@ func TestSelectFields(t *testing.T) {
 		assert.NotEqual(t, "", user.Name.FirstName)
 	}
 
-	assert.Equal(t, 18, len(users))
+	assert.Equal(t, 19, len(users))
 }
 
 func TestStructCondition(t *testing.T) {
+	db := newTestDB(t, "users")
+
 	userCondition := User{
 		Name: Name{
 			FirstName: "User 10",
@@ -307,6 +318,8 @@ func TestStructCondition(t *testing.T) {
 }
 
 func TestMapCondition(t *testing.T) {
+	db := newTestDB(t, "users")
+
 	userCondition := map[string]interface{}{
 		"middle_name": "",
 		"first_name":  []string{"User 10", "User 11"},
@@ -319,6 +332,8 @@ func TestMapCondition(t *testing.T) {
 }
 
 func TestOrderLimitOffset(t *testing.T) {
+	db := newTestDB(t, "users")
+
 	var users []User
 	err := db.Order("id asc, first_name desc").Limit(5).Offset(5).Find(&users).Error
 	assert.Nil(t, err)
@@ -332,13 +347,17 @@ type UserResponse struct {
 }
 
 func TestQueryNonModel(t *testing.T) {
+	db := newTestDB(t, "users")
+
 	var users []UserResponse
 	err := db.Model(&User{}).Select("id, first_name, last_name").Find(&users).Error
 	assert.Nil(t, err)
-	assert.Equal(t, 18, len(users))
+	assert.Equal(t, 19, len(users))
 }
 
 func TestUpdate(t *testing.T) {
+	db := newTestDB(t, "users")
+
 	var user User
 	err := db.Where("id = ?", 10).Find(&user).Error
 	assert.Nil(t, err)
@@ -349,13 +368,16 @@ func TestUpdate(t *testing.T) {
 	err = db.Save(&user).Error
 	assert.Nil(t, err)
 
-	db.Where("id =?", 10).Where("password = ?", "newpassword").Find(&user)
+	user = User{}
+	db.Where("id =?", 10).Find(&user)
 	assert.Equal(t, "10", user.ID)
-	assert.Equal(t, "newpassword", user.Password)
+	assert.Nil(t, user.VerifyPassword(nil, "newpassword"))
 	assert.Equal(t, "Sari", user.Name.FirstName)
 }
 
 func TestUpdateSelectionColumns(t *testing.T) {
+	db := newTestDB(t, "users")
+
 	err := db.Model(&User{}).Where("id = ?", 11).Updates(map[string]interface{}{
 		"first_name": "Celox",
 		"last_name":  "Dusk",
@@ -365,6 +387,25 @@ func TestUpdateSelectionColumns(t *testing.T) {
 	err = db.Model(&User{}).Where("id = ?", 12).Update("password", "hidden").Error
 	assert.Nil(t, err)
 
+	var user User
+	err = db.Take(&user, "id = ?", 12).Error
+	assert.Nil(t, err)
+	assert.Nil(t, user.VerifyPassword(nil, "hidden"))
+
+	err = db.Model(&User{}).Where("id = ?", 11).Updates(User{Password: "plain11"}).Error
+	assert.Nil(t, err)
+	user = User{}
+	err = db.Take(&user, "id = ?", 11).Error
+	assert.Nil(t, err)
+	assert.Nil(t, user.VerifyPassword(nil, "plain11"))
+
+	err = db.Model(&User{ID: "10"}).Updates(&User{Password: "plain10"}).Error
+	assert.Nil(t, err)
+	user = User{}
+	err = db.Take(&user, "id = ?", 10).Error
+	assert.Nil(t, err)
+	assert.Nil(t, user.VerifyPassword(nil, "plain10"))
+
 	db.Where("id = ?", 13).Updates(User{
 		Name: Name{
 			FirstName: "Anas",
@@ -374,6 +415,8 @@ func TestUpdateSelectionColumns(t *testing.T) {
 }
 
 func TestAutoIncrement(t *testing.T) {
+	db := newTestDB(t)
+
 	for i := 0; i < 10; i++ {
 		userLog := UserLog{
 			UserId: "1",
@@ -389,6 +432,8 @@ func TestAutoIncrement(t *testing.T) {
 }
 
 func TestCreateOrUpdate(t *testing.T) {
+	db := newTestDB(t)
+
 	userLog := UserLog{
 		UserId: "1",
 		Action: "Test Action",
@@ -403,6 +448,8 @@ func TestCreateOrUpdate(t *testing.T) {
 }
 
 func TestCreateOrUpdateNonAutoIncrement(t *testing.T) {
+	db := newTestDB(t)
+
 	user := User{
 		ID:   "99",
 		Name: Name{FirstName: "User 99"},
@@ -417,6 +464,8 @@ func TestCreateOrUpdateNonAutoIncrement(t *testing.T) {
 }
 
 func TestConflict(t *testing.T) {
+	db := newTestDB(t)
+
 	user := User{
 		ID:   "88",
 		Name: Name{FirstName: "User 88"},
@@ -427,8 +476,16 @@ func TestConflict(t *testing.T) {
 }
 
 func TestDelete(t *testing.T) {
+	db := newTestDB(t, "users")
+
+	err := db.Create(&[]User{
+		{ID: "88", Name: Name{FirstName: "User 88"}},
+		{ID: "99", Name: Name{FirstName: "User 99"}},
+	}).Error
+	assert.Nil(t, err)
+
 	var user User
-	err := db.Take(&user, "id = ?", "99").Error
+	err = db.Take(&user, "id = ?", "99").Error
 	assert.Nil(t, err)
 
 	err = db.Delete(&user).Error
@@ -439,9 +496,20 @@ func TestDelete(t *testing.T) {
 
 	err = db.Where("id = ?", "19").Delete(&User{}).Error
 	assert.Nil(t, err)
+
+	// Users are soft deleted and stay in the table.
+	var count int64
+	err = db.Model(&User{}).Where("id IN ?", []string{"88", "99"}).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+	err = db.Unscoped().Model(&User{}).Where("id IN ?", []string{"88", "99"}).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), count)
 }
 
 func TestSoftDelete(t *testing.T) {
+	db := newTestDB(t)
+
 	todo := Todo{
 		UserId:      "1",
 		Title:       "1",
@@ -461,6 +529,8 @@ func TestSoftDelete(t *testing.T) {
 }
 
 func TestUnscoped(t *testing.T) {
+	db := newTestDB(t, "todos")
+
 	var todo Todo
 	err := db.Unscoped().First(&todo, "id = ?", "3").Error
 	assert.Nil(t, err)
@@ -474,6 +544,8 @@ func TestUnscoped(t *testing.T) {
 }
 
 func TestLock(t *testing.T) {
+	db := newTestDB(t, "users")
+
 	err := db.Transaction(func(tx *gorm.DB) error {
 		var user User
 		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&user, "id = ?", "1").Error
@@ -490,6 +562,8 @@ func TestLock(t *testing.T) {
 }
 
 func TestCreateWallet(t *testing.T) {
+	db := newTestDB(t, "users")
+
 	wallet := Wallet{
 		ID:      "1",
 		UserId:  "1",
@@ -501,30 +575,41 @@ func TestCreateWallet(t *testing.T) {
 }
 
 func TestRetrieveRelation(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+
 	var user User
-	err := db.Model(User{}).Preload("Wallet").Take(&user, "id = ?", "1").Error
+	err := db.Model(User{}).Preload("Wallets").Take(&user, "id = ?", "1").Error
 	assert.Nil(t, err)
 	assert.Equal(t, "1", user.ID)
-	assert.Equal(t, "1", user.Wallet.ID)
+	assert.Equal(t, 1, len(user.Wallets))
+	assert.Equal(t, "1", user.Wallets[0].ID)
 }
 
 func TestRetrieveRelationJoin(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+
 	var user User
-	err := db.Model(User{}).Joins("Wallet").Take(&user, "users.id = ?", "1").Error
+	err := db.Model(User{}).Joins("join wallets on wallets.user_id = users.id AND wallets.currency = ?", "IDR").
+		Preload("Wallets").Take(&user, "users.id = ?", "1").Error
 	assert.Nil(t, err)
 	assert.Equal(t, "1", user.ID)
-	assert.Equal(t, "1", user.Wallet.ID)
+	assert.Equal(t, 1, len(user.Wallets))
+	assert.Equal(t, "1", user.Wallets[0].ID)
 }
 
 func TestAutoCreateUpdate(t *testing.T) {
+	db := newTestDB(t)
+
 	user := User{
 		ID:       "20",
 		Name:     Name{FirstName: "User 20"},
 		Password: "rahasia",
-		Wallet: Wallet{
-			ID:      "20",
-			UserId:  "20",
-			Balance: 1000000000,
+		Wallets: []Wallet{
+			{
+				ID:      "20",
+				UserId:  "20",
+				Balance: 1000000000,
+			},
 		},
 	}
 
@@ -533,14 +618,18 @@ func TestAutoCreateUpdate(t *testing.T) {
 }
 
 func TestSkipAutoCreateUpdate(t *testing.T) {
+	db := newTestDB(t)
+
 	user := User{
 		ID:       "21",
 		Name:     Name{FirstName: "User 21"},
 		Password: "rahasia",
-		Wallet: Wallet{
-			ID:      "21",
-			UserId:  "21",
-			Balance: 1000000000,
+		Wallets: []Wallet{
+			{
+				ID:      "21",
+				UserId:  "21",
+				Balance: 1000000000,
+			},
 		},
 	}
 
@@ -549,14 +638,18 @@ func TestSkipAutoCreateUpdate(t *testing.T) {
 }
 
 func TestUserAndAddresses(t *testing.T) {
+	db := newTestDB(t)
+
 	user := User{
 		ID:       "23",
 		Name:     Name{FirstName: "User 23"},
 		Password: "rahasia",
-		Wallet: Wallet{
-			ID:      "23",
-			UserId:  "23",
-			Balance: 1000000000,
+		Wallets: []Wallet{
+			{
+				ID:      "23",
+				UserId:  "23",
+				Balance: 1000000000,
+			},
 		},
 		Addresses: []Address{
 			{
@@ -575,20 +668,26 @@ func TestUserAndAddresses(t *testing.T) {
 }
 
 func TestPreloadJoinOneToMany(t *testing.T) {
+	db := newTestDB(t, "users", "wallets", "addresses")
+
 	var users []User
 	err := db.Model(&User{}).Preload("Addresses").
-		Joins("Wallet").Find(&users).Error
+		Joins("join wallets on wallets.user_id = users.id").Find(&users).Error
 	assert.Nil(t, err)
 }
 
 func TestTakePreloadJoinOneToMany(t *testing.T) {
+	db := newTestDB(t, "users", "wallets", "addresses")
+
 	var user User
 	err := db.Model(&User{}).Preload("Addresses").
-		Joins("Wallet").Take(&user, "users.id = ?", "20").Error
+		Joins("join wallets on wallets.user_id = users.id").Take(&user, "users.id = ?", "20").Error
 	assert.Nil(t, err)
 }
 
 func TestBelongsTo(t *testing.T) {
+	db := newTestDB(t, "users", "addresses")
+
 	fmt.Println("Preload")
 	var addresses []Address
 	err := db.Model(&Address{}).Preload("User").Find(&addresses).Error
@@ -602,6 +701,8 @@ func TestBelongsTo(t *testing.T) {
 }
 
 func TestBelongsToWallet(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+
 	fmt.Println("Preload")
 	var wallets []Wallet
 	err := db.Model(&Wallet{}).Preload("User").Find(&wallets).Error
@@ -613,6 +714,8 @@ func TestBelongsToWallet(t *testing.T) {
 }
 
 func TestCreateManyToMany(t *testing.T) {
+	db := newTestDB(t, "users")
+
 	product := Product{
 		ID:    "P001",
 		Name:  "Contoh Produk",
@@ -636,6 +739,8 @@ func TestCreateManyToMany(t *testing.T) {
 }
 
 func TestPreloadManyToMany(t *testing.T) {
+	db := newTestDB(t, "users", "products", "user_like_product")
+
 	var product Product
 	err := db.Preload("LikedByUsers").Take(&product, "id = ?", "P001").Error
 	assert.Nil(t, err)
@@ -643,6 +748,8 @@ func TestPreloadManyToMany(t *testing.T) {
 }
 
 func TestPreloadManyToManyUser(t *testing.T) {
+	db := newTestDB(t, "users", "products", "user_like_product")
+
 	var user User
 	err := db.Preload("LikeProducts").Take(&user, "id = ?", "1").Error
 	assert.Nil(t, err)
@@ -650,6 +757,8 @@ func TestPreloadManyToManyUser(t *testing.T) {
 }
 
 func TestAssociationFind(t *testing.T) {
+	db := newTestDB(t, "users", "products", "user_like_product")
+
 	var product Product
 	err := db.Take(&product, "id = ?", "P001").Error
 	assert.Nil(t, err)
@@ -661,6 +770,8 @@ func TestAssociationFind(t *testing.T) {
 }
 
 func TestAssociationAdd(t *testing.T) {
+	db := newTestDB(t, "users", "products", "user_like_product")
+
 	var product Product
 	err := db.Take(&product, "id = ?", "P001").Error
 	assert.Nil(t, err)
@@ -674,26 +785,31 @@ func TestAssociationAdd(t *testing.T) {
 }
 
 func TestAssociationReplace(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+
 	err := db.Transaction(func(tx *gorm.DB) error {
 		var user User
-		err := db.Take(&user, "id = ?", "1").Error
+		err := tx.Take(&user, "id = ?", "1").Error
 		if err != nil {
 			return err
 		}
 
 		wallet := Wallet{
-			ID:      "01",
-			UserId:  user.ID,
-			Balance: 1000000,
+			ID:       "10",
+			UserId:   user.ID,
+			Balance:  1000000,
+			Currency: "USD",
 		}
 
-		err = db.Model(&user).Association("Wallet").Replace(&wallet)
+		err = tx.Model(&user).Association("Wallets").Replace(&wallet)
 		return err
 	})
 	assert.Nil(t, err)
 }
 
 func TestAssociationDelete(t *testing.T) {
+	db := newTestDB(t, "users", "products", "user_like_product")
+
 	var product Product
 	err := db.Take(&product, "id = ?", "P001").Error
 	assert.Nil(t, err)
@@ -707,6 +823,8 @@ func TestAssociationDelete(t *testing.T) {
 }
 
 func TestAssociationClear(t *testing.T) {
+	db := newTestDB(t, "users", "products", "user_like_product")
+
 	var product Product
 	err := db.Take(&product, "id = ?", "P001").Error
 	assert.Nil(t, err)
@@ -720,14 +838,18 @@ func TestAssociationClear(t *testing.T) {
 }
 
 func TestPreloadingWithCondition(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+
 	var user User
-	err := db.Preload("Wallet", "balance > ?", 100).Take(&user, "id = ?", "1").Error
+	err := db.Preload("Wallets", "balance > ?", 100).Take(&user, "id = ?", "1").Error
 	assert.Nil(t, err)
 
 	fmt.Println(user)
 }
 
 func TestPreloadingNested(t *testing.T) {
+	db := newTestDB(t, "users", "wallets", "addresses")
+
 	var wallet Wallet
 	err := db.Preload("User.Addresses").Take(&wallet, "id = ?", "22").Error
 	assert.Nil(t, err)
@@ -738,38 +860,46 @@ func TestPreloadingNested(t *testing.T) {
 }
 
 func TestPreloadingAll(t *testing.T) {
+	db := newTestDB(t, "users", "wallets", "addresses", "products", "user_like_product")
+
 	var user User
 	err := db.Preload(clause.Associations).Take(&user, "id = ?", "1").Error
 	assert.Nil(t, err)
 }
 
 func TestJoinQuery(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+
 	var users []User
 	err := db.Joins("join wallets on wallets.user_id = users.id").Find(&users).Error
 	assert.Nil(t, err)
 	assert.Equal(t, 5, len(users))
 
 	users = []User{}
-	err = db.Joins("Wallet").Find(&users).Error
+	err = db.Joins("left join wallets on wallets.user_id = users.id").Find(&users).Error
 	assert.Nil(t, err)
-	assert.Equal(t, 22, len(users))
+	assert.Equal(t, 19, len(users))
 }
 
 func TestJoinQueryCondition(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+
 	var users []User
 	err := db.Joins("join wallets on wallets.user_id = users.id AND wallets.balance > ?", 500000).Find(&users).Error
 	assert.Nil(t, err)
 	assert.Equal(t, 5, len(users))
 
 	users = []User{}
-	err = db.Joins("Wallet").Where("Wallet.balance > ?", 500000).Find(&users).Error
+	err = db.Joins("join wallets on wallets.user_id = users.id").Where("wallets.balance > ?", 500000).Find(&users).Error
 	assert.Nil(t, err)
 	assert.Equal(t, 5, len(users))
 }
 
 func TestCount(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+
 	var count int64
-	err := db.Model(&User{}).Joins("Wallet").Where("Wallet.balance > ?", 500000).
+	err := db.Model(&User{}).Joins("join wallets on wallets.user_id = users.id").Where("wallets.balance > ?", 500000).
 		Count(&count).Error
 	assert.Nil(t, err)
 	assert.Equal(t, count, int64(5))
@@ -783,6 +913,8 @@ type AggregationResult struct {
 }
 
 func TestAggregation(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+
 	var result AggregationResult
 	err := db.Model(&Wallet{}).Select("sum(balance) as total_balance",
 		"min(balance) as min_balance", "max(balance) as max_balance",
@@ -798,6 +930,8 @@ func TestAggregation(t *testing.T) {
 }
 
 func TestAggregationGroupByAndHavingBy(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+
 	var results []AggregationResult
 	err := db.Model(&Wallet{}).Select("sum(balance) as total_balance",
 		"min(balance) as min_balance", "max(balance) as max_balance",
@@ -808,12 +942,14 @@ func TestAggregationGroupByAndHavingBy(t *testing.T) {
 }
 
 func TestContext(t *testing.T) {
+	db := newTestDB(t, "users")
+
 	ctx := context.Background()
 
 	var users []User
 	err := db.WithContext(ctx).Model(&User{}).Find(&users).Error
 	assert.Nil(t, err)
-	assert.Equal(t, 21, len(users))
+	assert.Equal(t, 19, len(users))
 }
 
 func BrokeWalletBalance(db *gorm.DB) *gorm.DB {
@@ -825,6 +961,8 @@ func SultanWalletBalance(db *gorm.DB) *gorm.DB {
 }
 
 func TestScope(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+
 	var wallets []Wallet
 	err := db.Model(&Wallet{}).Scopes(BrokeWalletBalance).Find(&wallets).Error
 	assert.Nil(t, err)
@@ -836,11 +974,15 @@ func TestScope(t *testing.T) {
 }
 
 func TestMigrator(t *testing.T) {
+	db := newTestDB(t)
+
 	err := db.Migrator().AutoMigrate(&GuestBook{})
 	assert.Nil(t, err)
 }
 
 func TestHook(t *testing.T) {
+	db := newTestDB(t)
+
 	user := User{
 		Password: "rahasia",
 		Name:     Name{FirstName: "User 100"},
@@ -0,0 +1,35 @@
+package golang_gorm
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+type GuestBookRepository interface {
+	FindByID(ctx context.Context, id int64) (GuestBook, error)
+	// Latest returns the limit most recent entries, newest first.
+	Latest(ctx context.Context, limit int) ([]GuestBook, error)
+	FindByEmail(ctx context.Context, email string) ([]GuestBook, error)
+	// FindKeyset lists guest book entries page by page; see Keyset.
+	FindKeyset(ctx context.Context, keyset Keyset, scopes ...Scope) (CursorPage[GuestBook], error)
+	Create(ctx context.Context, guestBook *GuestBook) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type guestBookRepository struct {
+	*Repository[GuestBook, int64]
+}
+
+func NewGuestBookRepository(db *gorm.DB) GuestBookRepository {
+	return &guestBookRepository{Repository: NewRepository[GuestBook, int64](db)}
+}
+
+func (r *guestBookRepository) Latest(ctx context.Context, limit int) ([]GuestBook, error) {
+	return r.FindAll(ctx, OrderBy("created_at desc, id desc"), func(db *gorm.DB) *gorm.DB {
+		return db.Limit(limit)
+	})
+}
+
+func (r *guestBookRepository) FindByEmail(ctx context.Context, email string) ([]GuestBook, error) {
+	return r.FindAll(ctx, Where("email = ?", email), OrderBy("id asc"))
+}
@@ -0,0 +1,108 @@
+package golang_gorm
+
+import (
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func init() {
+	PasswordCost = bcrypt.MinCost
+}
+
+// newTestDB gives the test its own freshly migrated database and loads the
+// named fixtures from testdata/fixtures into it. The database is an SQLite
+// file in the test's temporary directory unless TEST_DB_DRIVER=mysql, in which
+// case the database from the DB_* environment variables is dropped and
+// recreated instead. Like OpenConnection, it audits changes with AuditPlugin
+// and cascades soft deletes with SoftDeletePlugin.
+func newTestDB(t *testing.T, fixtures ...string) *gorm.DB {
+	t.Helper()
+
+	var db *gorm.DB
+	if os.Getenv("TEST_DB_DRIVER") == "mysql" {
+		db = openMySQLTestDB(t)
+	} else {
+		db = openSQLiteTestDB(t)
+	}
+
+	err := db.AutoMigrate(Models()...)
+	assert.Nil(t, err)
+	err = db.Table("sample").AutoMigrate(&Sample{})
+	assert.Nil(t, err)
+
+	loader, err := NewFixtureLoader(db, os.DirFS("testdata/fixtures"))
+	assert.Nil(t, err)
+	if err := loader.Load(fixtures...); err != nil {
+		t.Fatal(err)
+	}
+	// Registered after the fixtures so they do not fill user_logs; the MySQL
+	// connection from OpenConnection has it already.
+	if _, ok := db.Plugins["audit"]; !ok {
+		err = db.Use(NewAuditPlugin())
+		assert.Nil(t, err)
+	}
+	if _, ok := db.Plugins["soft_delete"]; !ok {
+		err = db.Use(NewSoftDeletePlugin())
+		assert.Nil(t, err)
+	}
+
+	return db
+}
+
+func openSQLiteTestDB(t *testing.T) *gorm.DB {
+	dsn := filepath.Join(t.TempDir(), "golang_gorm.db") +
+		"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: RedactPasswords(logger.Default.LogMode(logger.Info)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = sqlDB.Close()
+	})
+
+	return db
+}
+
+func openMySQLTestDB(t *testing.T) *gorm.DB {
+	config, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := OpenConnection(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tables := []interface{}{"user_like_product", "sample", "schema_migrations"}
+	for i := len(Models()) - 1; i >= 0; i-- {
+		tables = append(tables, Models()[i])
+	}
+	err = db.Migrator().DropTable(tables...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = sqlDB.Close()
+	})
+
+	return db
+}
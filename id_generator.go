@@ -0,0 +1,190 @@
+package golang_gorm
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// IDGenerator produces primary keys for the models with string IDs (User,
+// Wallet and Product). Implementations must be safe for concurrent use and
+// never return the same ID twice, even within one millisecond.
+type IDGenerator interface {
+	NewID() string
+}
+
+// DefaultIDGenerator is used by the BeforeCreate hooks of the models.
+var DefaultIDGenerator IDGenerator = NewULIDGenerator()
+
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDGenerator returns 26 character ULIDs. IDs made in the same millisecond
+// increment the random part, so they stay unique and sortable.
+type ULIDGenerator struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	last    uint64
+	entropy [10]byte
+}
+
+func NewULIDGenerator() *ULIDGenerator {
+	return &ULIDGenerator{now: time.Now}
+}
+
+func (g *ULIDGenerator) NewID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := uint64(g.now().UnixMilli())
+	if ms > g.last {
+		g.last = ms
+		randomFill(g.entropy[:])
+	} else if !increment(g.entropy[:]) {
+		// The random part overflowed; borrow the next millisecond.
+		g.last++
+		randomFill(g.entropy[:])
+	}
+
+	var id [16]byte
+	id[0] = byte(g.last >> 40)
+	id[1] = byte(g.last >> 32)
+	id[2] = byte(g.last >> 24)
+	id[3] = byte(g.last >> 16)
+	id[4] = byte(g.last >> 8)
+	id[5] = byte(g.last)
+	copy(id[6:], g.entropy[:])
+
+	return encodeCrockford(id)
+}
+
+// UUIDv7Generator returns RFC 9562 version 7 UUIDs. The 12 bit rand_a field
+// is used as a counter within one millisecond.
+type UUIDv7Generator struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	last    uint64
+	counter uint16
+}
+
+func NewUUIDv7Generator() *UUIDv7Generator {
+	return &UUIDv7Generator{now: time.Now}
+}
+
+func (g *UUIDv7Generator) NewID() string {
+	g.mu.Lock()
+	ms := uint64(g.now().UnixMilli())
+	if ms > g.last {
+		g.last = ms
+		var seed [2]byte
+		randomFill(seed[:])
+		// Start low in the counter space to leave room for increments.
+		g.counter = binary.BigEndian.Uint16(seed[:]) & 0x07ff
+	} else {
+		g.counter++
+		if g.counter > 0x0fff {
+			g.last++
+			g.counter = 0
+		}
+	}
+	last, counter := g.last, g.counter
+	g.mu.Unlock()
+
+	var id [16]byte
+	id[0] = byte(last >> 40)
+	id[1] = byte(last >> 32)
+	id[2] = byte(last >> 24)
+	id[3] = byte(last >> 16)
+	id[4] = byte(last >> 8)
+	id[5] = byte(last)
+	id[6] = 0x70 | byte(counter>>8)
+	id[7] = byte(counter)
+	randomFill(id[8:])
+	id[8] = 0x80 | id[8]&0x3f
+
+	var text [36]byte
+	hex.Encode(text[0:8], id[0:4])
+	text[8] = '-'
+	hex.Encode(text[9:13], id[4:6])
+	text[13] = '-'
+	hex.Encode(text[14:18], id[6:8])
+	text[18] = '-'
+	hex.Encode(text[19:23], id[8:10])
+	text[23] = '-'
+	hex.Encode(text[24:], id[10:])
+	return string(text[:])
+}
+
+// SnowflakeEpoch is the custom epoch of SnowflakeGenerator, 2024-01-01 UTC.
+var SnowflakeEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// SnowflakeGenerator returns 63 bit IDs made of 41 bits of milliseconds since
+// SnowflakeEpoch, a 10 bit node and a 12 bit sequence, formatted in decimal.
+// Every process writing to the same tables needs its own node.
+type SnowflakeGenerator struct {
+	mu       sync.Mutex
+	now      func() time.Time
+	node     int64
+	last     int64
+	sequence int64
+}
+
+func NewSnowflakeGenerator(node int64) (*SnowflakeGenerator, error) {
+	if node < 0 || node > 1023 {
+		return nil, errors.New("snowflake node must be between 0 and 1023")
+	}
+	return &SnowflakeGenerator{now: time.Now, node: node}, nil
+}
+
+func (g *SnowflakeGenerator) NewID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := g.now().Sub(SnowflakeEpoch).Milliseconds()
+	if ms > g.last {
+		g.last = ms
+		g.sequence = 0
+	} else {
+		g.sequence = (g.sequence + 1) & 0x0fff
+		if g.sequence == 0 {
+			// 4096 IDs in one millisecond, or the clock went back.
+			g.last++
+		}
+	}
+
+	return strconv.FormatInt(g.last<<22|g.node<<12|g.sequence, 10)
+}
+
+func randomFill(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+}
+
+// increment adds one to a big endian number and reports false on overflow.
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeCrockford(id [16]byte) string {
+	high := binary.BigEndian.Uint64(id[:8])
+	low := binary.BigEndian.Uint64(id[8:])
+
+	// 128 bits in 26 characters of 5 bits: the first character carries 3 bits.
+	var text [26]byte
+	for i := 25; i >= 0; i-- {
+		text[i] = crockfordAlphabet[low&0x1f]
+		low = low>>5 | high<<59
+		high >>= 5
+	}
+	return string(text[:])
+}
@@ -0,0 +1,124 @@
+package golang_gorm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestSnowflakeGenerator(t *testing.T) IDGenerator {
+	generator, err := NewSnowflakeGenerator(7)
+	assert.Nil(t, err)
+	return generator
+}
+
+func TestIDGeneratorsUniqueAndOrdered(t *testing.T) {
+	frozen := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	now := func() time.Time { return frozen }
+
+	ulid := NewULIDGenerator()
+	ulid.now = now
+	uuid := NewUUIDv7Generator()
+	uuid.now = now
+	snowflake, err := NewSnowflakeGenerator(7)
+	assert.Nil(t, err)
+	snowflake.now = now
+
+	generators := map[string]IDGenerator{"ulid": ulid, "uuidv7": uuid, "snowflake": snowflake}
+	for name, generator := range generators {
+		t.Run(name, func(t *testing.T) {
+			var ids []string
+			for i := 0; i < 10000; i++ {
+				ids = append(ids, generator.NewID())
+			}
+
+			sorted := sort.SliceIsSorted(ids, func(i, j int) bool {
+				if name == "snowflake" {
+					a, _ := strconv.ParseInt(ids[i], 10, 64)
+					b, _ := strconv.ParseInt(ids[j], 10, 64)
+					return a < b
+				}
+				return ids[i] < ids[j]
+			})
+			assert.True(t, sorted)
+			assert.Equal(t, len(ids), len(unique(ids)))
+		})
+	}
+}
+
+func TestIDGeneratorsConcurrent(t *testing.T) {
+	generators := map[string]IDGenerator{
+		"ulid":      NewULIDGenerator(),
+		"uuidv7":    NewUUIDv7Generator(),
+		"snowflake": newTestSnowflakeGenerator(t),
+	}
+	for name, generator := range generators {
+		t.Run(name, func(t *testing.T) {
+			var mu sync.Mutex
+			var wg sync.WaitGroup
+			var ids []string
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					local := make([]string, 0, 2000)
+					for j := 0; j < 2000; j++ {
+						local = append(local, generator.NewID())
+					}
+					mu.Lock()
+					ids = append(ids, local...)
+					mu.Unlock()
+				}()
+			}
+			wg.Wait()
+			assert.Equal(t, 16000, len(unique(ids)))
+		})
+	}
+}
+
+func TestIDFormats(t *testing.T) {
+	assert.Regexp(t, regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`), NewULIDGenerator().NewID())
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), NewUUIDv7Generator().NewID())
+	assert.Regexp(t, regexp.MustCompile(`^[0-9]+$`), newTestSnowflakeGenerator(t).NewID())
+
+	_, err := NewSnowflakeGenerator(1024)
+	assert.NotNil(t, err)
+}
+
+func TestBatchCreateGeneratesIDs(t *testing.T) {
+	db := newTestDB(t)
+
+	var users []User
+	for i := 0; i < 50; i++ {
+		users = append(users, User{
+			Password: "rahasia",
+			Name:     Name{FirstName: "User " + strconv.Itoa(i)},
+			Wallets:  []Wallet{{Balance: 1000}},
+		})
+	}
+	err := db.Create(&users).Error
+	assert.Nil(t, err)
+
+	var count int64
+	err = db.Model(&Wallet{}).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(50), count)
+
+	products := []Product{{Name: "A"}, {Name: "B"}}
+	err = db.Create(&products).Error
+	assert.Nil(t, err)
+	assert.NotEqual(t, products[0].ID, products[1].ID)
+	assert.Regexp(t, "^Product-", products[0].ID)
+}
+
+func unique(ids []string) map[string]bool {
+	seen := map[string]bool{}
+	for _, id := range ids {
+		seen[id] = true
+	}
+	return seen
+}
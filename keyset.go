@@ -0,0 +1,259 @@
+package golang_gorm
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"reflect"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// SortKey is one column of a keyset ordering, named by column or field name.
+// The column must never be NULL: SQL compares NULL with nothing, so the seek
+// would skip those rows. Fields that can hold NULL, pointers and types such as
+// sql.NullString, are refused, but a NULL scanned into a plain field cannot be
+// told apart. Do not sort on columns like middle_name.
+type SortKey struct {
+	Column string
+	Desc   bool
+}
+
+// Keyset asks for the Limit rows after, or before, Cursor in Order. Unlike
+// Offset it seeks straight to the cursor through the index, and a row
+// inserted on an earlier page does not shift the later ones.
+//
+// The primary key is appended to Order when it is not already last, so rows
+// sharing the other sort values still have one fixed order.
+type Keyset struct {
+	Order  []SortKey
+	Cursor string
+	Limit  int
+}
+
+// CursorPage is one page of a keyset listing. Next and Prev are empty when
+// there is nothing further in that direction.
+type CursorPage[T any] struct {
+	Items []T
+	Next  string
+	Prev  string
+}
+
+type cursor struct {
+	Values   []json.RawMessage `json:"v"`
+	Backward bool              `json:"b,omitempty"`
+}
+
+// Scope applies the keyset to a query on model: the seek condition, the
+// order, and a limit of one extra row that tells whether another page exists.
+// FindKeyset wraps it and builds the cursors.
+func (k Keyset) Scope(model interface{}) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		_, keys, position, err := k.parse(db, model)
+		if err != nil {
+			db.AddError(err)
+			return db
+		}
+		return k.apply(db, keys, position)
+	}
+}
+
+func (k Keyset) apply(db *gorm.DB, keys []SortKey, position *position) *gorm.DB {
+	backward := position != nil && position.backward
+	if position != nil {
+		db = db.Where(seekCondition(keys, position.values, backward))
+	}
+	for _, key := range keys {
+		db = db.Order(clause.OrderByColumn{Column: keyColumn(key), Desc: key.Desc != backward})
+	}
+	return db.Limit(k.Limit + 1)
+}
+
+// FindKeyset returns one page of T in keyset order, narrowed by scopes.
+func FindKeyset[T any](ctx context.Context, db *gorm.DB, keyset Keyset, scopes ...Scope) (CursorPage[T], error) {
+	if keyset.Limit < 1 {
+		return CursorPage[T]{}, errors.New("keyset limit must be positive")
+	}
+	sch, keys, position, err := keyset.parse(db, new(T))
+	if err != nil {
+		return CursorPage[T]{}, err
+	}
+	backward := position != nil && position.backward
+
+	query := FromContext(ctx, db)
+	for _, scope := range scopes {
+		query = query.Scopes(scope)
+	}
+	var items []T
+	err = keyset.apply(query, keys, position).Find(&items).Error
+	if err != nil {
+		return CursorPage[T]{}, Classify(err)
+	}
+
+	more := len(items) > keyset.Limit
+	if more {
+		items = items[:keyset.Limit]
+	}
+	if backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	page := CursorPage[T]{Items: items}
+	if len(items) == 0 {
+		return page, nil
+	}
+	hasNext, hasPrev := more, keyset.Cursor != ""
+	if backward {
+		hasNext, hasPrev = true, more
+	}
+	if hasNext {
+		page.Next, err = encodeCursor(ctx, sch, keys, &items[len(items)-1], false)
+		if err != nil {
+			return CursorPage[T]{}, err
+		}
+	}
+	if hasPrev {
+		page.Prev, err = encodeCursor(ctx, sch, keys, &items[0], true)
+		if err != nil {
+			return CursorPage[T]{}, err
+		}
+	}
+	return page, nil
+}
+
+func (k Keyset) parse(db *gorm.DB, model interface{}) (*schema.Schema, []SortKey, *position, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, nil, nil, err
+	}
+	sch := stmt.Schema
+	if sch.PrioritizedPrimaryField == nil {
+		return nil, nil, nil, fmt.Errorf("keyset on %s needs a single primary key", sch.Table)
+	}
+
+	keys := append([]SortKey{}, k.Order...)
+	for i, key := range keys {
+		field := keyField(sch, key)
+		if field == nil || field.DBName == "" {
+			return nil, nil, nil, fmt.Errorf("keyset on %s: unknown column %s", sch.Table, key.Column)
+		}
+		if nullable(field) {
+			return nil, nil, nil, fmt.Errorf("keyset on %s: column %s can be NULL", sch.Table, key.Column)
+		}
+		keys[i].Column = field.DBName
+	}
+	if len(keys) == 0 || keyField(sch, keys[len(keys)-1]) != sch.PrioritizedPrimaryField {
+		desc := len(keys) > 0 && keys[len(keys)-1].Desc
+		keys = append(keys, SortKey{Column: sch.PrioritizedPrimaryField.DBName, Desc: desc})
+	}
+
+	position, err := decodeCursor(k.Cursor, sch, keys)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return sch, keys, position, nil
+}
+
+func keyField(sch *schema.Schema, key SortKey) *schema.Field {
+	column := key.Column
+	if table, name, ok := strings.Cut(column, "."); ok && table == sch.Table {
+		column = name
+	}
+	return sch.LookUpField(column)
+}
+
+// nullable reports whether the field's type can hold NULL: a pointer, or a
+// struct with a Valid flag like sql.NullString and gorm.DeletedAt.
+func nullable(field *schema.Field) bool {
+	if field.FieldType.Kind() == reflect.Ptr {
+		return true
+	}
+	if field.FieldType.Kind() != reflect.Struct {
+		return false
+	}
+	valid, ok := field.FieldType.FieldByName("Valid")
+	return ok && valid.Type.Kind() == reflect.Bool
+}
+
+// position is a decoded cursor.
+type position struct {
+	values   []interface{}
+	backward bool
+}
+
+func decodeCursor(text string, sch *schema.Schema, keys []SortKey) (*position, error) {
+	if text == "" {
+		return nil, nil
+	}
+	content, err := base64.RawURLEncoding.DecodeString(text)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var decoded cursor
+	if err := json.Unmarshal(content, &decoded); err != nil || len(decoded.Values) != len(keys) {
+		return nil, ErrInvalidCursor
+	}
+
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		value := reflect.New(keyField(sch, key).FieldType)
+		if err := json.Unmarshal(decoded.Values[i], value.Interface()); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		values[i] = value.Elem().Interface()
+	}
+	return &position{values: values, backward: decoded.Backward}, nil
+}
+
+func encodeCursor(ctx context.Context, sch *schema.Schema, keys []SortKey, item interface{}, backward bool) (string, error) {
+	row := reflect.Indirect(reflect.ValueOf(item))
+	encoded := cursor{Values: make([]json.RawMessage, len(keys)), Backward: backward}
+	for i, key := range keys {
+		value, _ := keyField(sch, key).ValueOf(ctx, row)
+		content, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		encoded.Values[i] = content
+	}
+
+	content, err := json.Marshal(encoded)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(content), nil
+}
+
+// seekCondition builds (a > ?) OR (a = ? AND b > ?) OR ..., with each
+// comparison flipped for descending keys and again when going backward.
+func seekCondition(keys []SortKey, values []interface{}, backward bool) clause.Expression {
+	var alternatives []clause.Expression
+	for i, key := range keys {
+		var conditions []clause.Expression
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, clause.Eq{Column: keyColumn(keys[j]), Value: values[j]})
+		}
+		column := keyColumn(key)
+		if key.Desc != backward {
+			conditions = append(conditions, clause.Lt{Column: column, Value: values[i]})
+		} else {
+			conditions = append(conditions, clause.Gt{Column: column, Value: values[i]})
+		}
+		alternatives = append(alternatives, clause.And(conditions...))
+	}
+	return clause.Or(alternatives...)
+}
+
+// keyColumn is the column of a parsed key, on the queried table so joins do
+// not make it ambiguous.
+func keyColumn(key SortKey) clause.Column {
+	return clause.Column{Table: clause.CurrentTable, Name: key.Column}
+}
@@ -0,0 +1,145 @@
+package golang_gorm
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func userIDs(users []User) []string {
+	ids := make([]string, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	return ids
+}
+
+func TestKeysetPagination(t *testing.T) {
+	db := newTestDB(t, "users")
+	users := NewUserRepository(db)
+	ctx := context.Background()
+
+	var expected []User
+	err := db.Order("first_name desc, id desc").Find(&expected).Error
+	assert.Nil(t, err)
+
+	keyset := Keyset{Order: []SortKey{{Column: "first_name", Desc: true}}, Limit: 5}
+	var pages []CursorPage[User]
+	var walked []string
+	for {
+		page, err := users.FindKeyset(ctx, keyset)
+		assert.Nil(t, err)
+		pages = append(pages, page)
+		walked = append(walked, userIDs(page.Items)...)
+		if page.Next == "" {
+			break
+		}
+		keyset.Cursor = page.Next
+	}
+	assert.Equal(t, userIDs(expected), walked)
+	assert.Equal(t, 4, len(pages))
+	assert.Equal(t, "", pages[0].Prev)
+	assert.Equal(t, 4, len(pages[3].Items))
+
+	// Walk back from the last page.
+	keyset.Cursor = pages[3].Prev
+	for i := 2; i >= 0; i-- {
+		page, err := users.FindKeyset(ctx, keyset)
+		assert.Nil(t, err)
+		assert.Equal(t, userIDs(pages[i].Items), userIDs(page.Items))
+		assert.NotEqual(t, "", page.Next)
+		keyset.Cursor = page.Prev
+	}
+	assert.Equal(t, "", keyset.Cursor)
+}
+
+func TestKeysetStableUnderInserts(t *testing.T) {
+	db := newTestDB(t, "users")
+	users := NewUserRepository(db)
+	ctx := context.Background()
+
+	first, err := users.FindKeyset(ctx, Keyset{Limit: 5})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1", "10", "11", "12", "13"}, userIDs(first.Items))
+
+	// Sorts onto the first page, which would push "13" onto the second page
+	// with Offset.
+	err = users.Create(ctx, &User{ID: "0", Password: "rahasia", Name: Name{FirstName: "User 0"}})
+	assert.Nil(t, err)
+
+	second, err := users.FindKeyset(ctx, Keyset{Cursor: first.Next, Limit: 5})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"17", "18", "2", "20", "21"}, userIDs(second.Items))
+}
+
+func TestKeysetListings(t *testing.T) {
+	db := newTestDB(t, "users", "todos")
+	repositories := NewRepositories(db)
+	ctx := context.Background()
+
+	todos, err := repositories.Todos.FindKeyset(ctx, Keyset{Order: []SortKey{{Column: "created_at"}}, Limit: 1}, Where("user_id = ?", "1"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(todos.Items))
+	todos, err = repositories.Todos.FindKeyset(ctx, Keyset{Order: []SortKey{{Column: "created_at"}}, Cursor: todos.Next, Limit: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, uint(2), todos.Items[0].ID)
+	assert.Equal(t, "", todos.Next)
+
+	for i := 0; i < 3; i++ {
+		err = repositories.GuestBooks.Create(ctx, &GuestBook{Name: "Guest", Email: "guest@example.com", Message: "Hello"})
+		assert.Nil(t, err)
+		err = repositories.UserLogs.Create(ctx, &UserLog{UserId: "1", Action: "Test Action"})
+		assert.Nil(t, err)
+	}
+
+	guestBooks, err := repositories.GuestBooks.FindKeyset(ctx, Keyset{Order: []SortKey{{Column: "id", Desc: true}}, Limit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), guestBooks.Items[0].ID)
+	assert.Equal(t, int64(2), guestBooks.Items[1].ID)
+
+	logs, err := repositories.UserLogs.FindKeyset(ctx, Keyset{Order: []SortKey{{Column: "created_at", Desc: true}}, Limit: 2})
+	assert.Nil(t, err)
+	logs, err = repositories.UserLogs.FindKeyset(ctx, Keyset{Order: []SortKey{{Column: "created_at", Desc: true}}, Cursor: logs.Next, Limit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(logs.Items))
+	assert.Equal(t, 1, logs.Items[0].ID)
+
+	_, err = repositories.UserLogs.FindKeyset(ctx, Keyset{Cursor: "not a cursor", Limit: 2})
+	assert.True(t, errors.Is(err, ErrInvalidCursor))
+	_, err = repositories.UserLogs.FindKeyset(ctx, Keyset{Order: []SortKey{{Column: "missing"}}, Limit: 2})
+	assert.NotNil(t, err)
+}
+
+func TestKeysetSortKeys(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+	users := NewUserRepository(db)
+	ctx := context.Background()
+
+	byColumn, err := users.FindKeyset(ctx, Keyset{Order: []SortKey{{Column: "first_name"}}, Limit: 3})
+	assert.Nil(t, err)
+	byField, err := users.FindKeyset(ctx, Keyset{Order: []SortKey{{Column: "FirstName"}}, Limit: 3})
+	assert.Nil(t, err)
+	assert.Equal(t, userIDs(byColumn.Items), userIDs(byField.Items))
+	byField, err = users.FindKeyset(ctx, Keyset{Order: []SortKey{{Column: "FirstName"}}, Cursor: byField.Next, Limit: 3})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(byField.Items))
+
+	// Sort columns stay on the model's table when the query joins another
+	// one with the same column names.
+	joined, err := users.FindKeyset(ctx, Keyset{Order: []SortKey{{Column: "users.created_at"}}, Limit: 2}, func(db *gorm.DB) *gorm.DB {
+		return db.Joins("join wallets on wallets.user_id = users.id")
+	})
+	assert.Nil(t, err)
+	joined, err = users.FindKeyset(ctx, Keyset{Order: []SortKey{{Column: "users.created_at"}}, Cursor: joined.Next, Limit: 2}, func(db *gorm.DB) *gorm.DB {
+		return db.Joins("join wallets on wallets.user_id = users.id")
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(joined.Items))
+
+	_, err = users.FindKeyset(ctx, Keyset{Order: []SortKey{{Column: "deleted_at"}}, Limit: 3})
+	assert.NotNil(t, err)
+	_, err = users.FindKeyset(ctx, Keyset{Order: []SortKey{{Column: "Wallets"}}, Limit: 3})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,334 @@
+package golang_gorm
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MigrationFiles holds the versioned MySQL migrations shipped with the
+// package. database.sql is the concatenation of every up migration.
+//
+//go:embed migrations/*.sql
+var MigrationFiles embed.FS
+
+// Migration is one numbered pair of NNNN_name.up.sql and NNNN_name.down.sql
+// files.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type SchemaMigration struct {
+	Version   int       `gorm:"primary_key;column:version;autoIncrement:false"`
+	Name      string    `gorm:"column:name"`
+	AppliedAt time.Time `gorm:"column:applied_at"`
+}
+
+func (s *SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// LoadMigrations reads every migration in the root of fsys, ordered by version.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration %s: file name must look like 0001_name.up.sql", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d: conflicting names %s and %s", version, migration.Name, match[2])
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if strings.TrimSpace(migration.Up) == "" || strings.TrimSpace(migration.Down) == "" {
+			return nil, fmt.Errorf("migration %d_%s: both up and down files are required", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// MigrationRunner applies migrations and records them in schema_migrations.
+// MySQL commits DDL implicitly, so a migration that fails halfway has to be
+// repaired by hand; the version is only recorded once all statements ran.
+type MigrationRunner struct {
+	db         *gorm.DB
+	migrations []Migration
+	readOnly   bool
+}
+
+var ErrReadOnlyRunner = errors.New("migration runner is read-only")
+
+func NewMigrationRunner(db *gorm.DB, fsys fs.FS) (*MigrationRunner, error) {
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Migrator().AutoMigrate(&SchemaMigration{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MigrationRunner{db: db, migrations: migrations}, nil
+}
+
+// NewReadOnlyMigrationRunner reports on migrations without writing to the
+// database, not even to create schema_migrations; a database without that
+// table has nothing applied. Up, Down and Redo return ErrReadOnlyRunner.
+func NewReadOnlyMigrationRunner(db *gorm.DB, fsys fs.FS) (*MigrationRunner, error) {
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &MigrationRunner{db: db, migrations: migrations, readOnly: true}, nil
+}
+
+// NewDefaultMigrationRunner runs the embedded MigrationFiles.
+func NewDefaultMigrationRunner(db *gorm.DB) (*MigrationRunner, error) {
+	fsys, err := fs.Sub(MigrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return NewMigrationRunner(db, fsys)
+}
+
+func (r *MigrationRunner) Status() ([]MigrationStatus, error) {
+	var applied []SchemaMigration
+	if !r.readOnly || r.db.Migrator().HasTable(&SchemaMigration{}) {
+		err := r.db.Order("version asc").Find(&applied).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	appliedAt := map[int]time.Time{}
+	for _, migration := range applied {
+		appliedAt[migration.Version] = migration.AppliedAt
+	}
+
+	statuses := make([]MigrationStatus, 0, len(r.migrations))
+	for _, migration := range r.migrations {
+		at, ok := appliedAt[migration.Version]
+		statuses = append(statuses, MigrationStatus{Migration: migration, Applied: ok, AppliedAt: at})
+	}
+	return statuses, nil
+}
+
+// Pending returns the migrations Up would apply, in order.
+func (r *MigrationRunner) Pending() ([]Migration, error) {
+	statuses, err := r.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, status := range statuses {
+		if !status.Applied {
+			pending = append(pending, status.Migration)
+		}
+	}
+	return pending, nil
+}
+
+// Latest returns the n most recently applied migrations, newest first, which
+// is what Down(n) would revert.
+func (r *MigrationRunner) Latest(n int) ([]Migration, error) {
+	statuses, err := r.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	var latest []Migration
+	for i := len(statuses) - 1; i >= 0 && len(latest) < n; i-- {
+		if statuses[i].Applied {
+			latest = append(latest, statuses[i].Migration)
+		}
+	}
+	return latest, nil
+}
+
+// Up applies every pending migration in version order and returns them.
+func (r *MigrationRunner) Up() ([]Migration, error) {
+	pending, err := r.Pending()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, migration := range pending {
+		if err := r.apply(migration); err != nil {
+			return applied, err
+		}
+		applied = append(applied, migration)
+	}
+	return applied, nil
+}
+
+// Down reverts the n most recently applied migrations, newest first.
+func (r *MigrationRunner) Down(n int) ([]Migration, error) {
+	latest, err := r.Latest(n)
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []Migration
+	for _, migration := range latest {
+		if err := r.revert(migration); err != nil {
+			return reverted, err
+		}
+		reverted = append(reverted, migration)
+	}
+	return reverted, nil
+}
+
+// Redo reverts the latest applied migration and applies it again.
+func (r *MigrationRunner) Redo() (Migration, error) {
+	reverted, err := r.Down(1)
+	if err != nil {
+		return Migration{}, err
+	}
+	if len(reverted) == 0 {
+		return Migration{}, errors.New("no migration has been applied")
+	}
+	return reverted[0], r.apply(reverted[0])
+}
+
+func (r *MigrationRunner) apply(migration Migration) error {
+	if r.readOnly {
+		return ErrReadOnlyRunner
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := execStatements(tx, migration.Up); err != nil {
+			return fmt.Errorf("migration %d_%s up: %w", migration.Version, migration.Name, err)
+		}
+		return tx.Create(&SchemaMigration{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			AppliedAt: time.Now(),
+		}).Error
+	})
+}
+
+func (r *MigrationRunner) revert(migration Migration) error {
+	if r.readOnly {
+		return ErrReadOnlyRunner
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := execStatements(tx, migration.Down); err != nil {
+			return fmt.Errorf("migration %d_%s down: %w", migration.Version, migration.Name, err)
+		}
+		return tx.Delete(&SchemaMigration{}, "version = ?", migration.Version).Error
+	})
+}
+
+func execStatements(tx *gorm.DB, script string) error {
+	for _, statement := range SplitStatements(script) {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SplitStatements splits a script on semicolons that are outside quotes and
+// drops "--" comment lines, since the MySQL driver runs one statement per Exec.
+func SplitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	var quote rune
+
+	for _, line := range strings.Split(script, "\n") {
+		if quote == 0 && strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		for _, char := range line {
+			switch {
+			case quote != 0 && char == quote:
+				quote = 0
+			case quote == 0 && (char == '\'' || char == '"' || char == '`'):
+				quote = char
+			case quote == 0 && char == ';':
+				if statement := strings.TrimSpace(current.String()); statement != "" {
+					statements = append(statements, statement)
+				}
+				current.Reset()
+				continue
+			}
+			current.WriteRune(char)
+		}
+		current.WriteRune('\n')
+	}
+
+	if statement := strings.TrimSpace(current.String()); statement != "" {
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+// NextMigrationVersion returns the version a new migration in fsys should use.
+func NextMigrationVersion(fsys fs.FS) (int, error) {
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return 0, err
+	}
+	if len(migrations) == 0 {
+		return 1, nil
+	}
+	return migrations[len(migrations)-1].Version + 1, nil
+}
+
+// MigrationFileName returns the file name of one direction of a migration.
+func MigrationFileName(version int, name, direction string) string {
+	return fmt.Sprintf("%04d_%s.%s.sql", version, name, direction)
+}
@@ -0,0 +1,79 @@
+package golang_gorm
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+	"reflect"
+	"time"
+)
+
+// sqlRecorder is a GORM logger that keeps the statements of a DryRun session
+// instead of printing them.
+type sqlRecorder struct {
+	statements []string
+}
+
+func (r *sqlRecorder) LogMode(logger.LogLevel) logger.Interface {
+	return r
+}
+
+func (r *sqlRecorder) Info(context.Context, string, ...interface{}) {}
+
+func (r *sqlRecorder) Warn(context.Context, string, ...interface{}) {}
+
+func (r *sqlRecorder) Error(context.Context, string, ...interface{}) {}
+
+func (r *sqlRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, sql)
+}
+
+// ModelSQL returns the CREATE TABLE statements GORM's Migrator would run for
+// models, join tables included, without touching a database. The dialector
+// only has to render SQL, so for MySQL open it with SkipInitializeWithVersion.
+func ModelSQL(dialector gorm.Dialector, models ...interface{}) ([]string, error) {
+	recorder := &sqlRecorder{}
+	db, err := gorm.Open(dialector, &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               recorder,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var joinTables []*schema.Schema
+	created := map[string]bool{}
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+		if err := db.Migrator().CreateTable(model); err != nil {
+			return nil, err
+		}
+		created[stmt.Schema.Table] = true
+
+		for _, relationship := range stmt.Schema.Relationships.Relations {
+			if relationship.JoinTable != nil {
+				joinTables = append(joinTables, relationship.JoinTable)
+			}
+		}
+	}
+
+	for _, joinTable := range joinTables {
+		if created[joinTable.Table] {
+			continue
+		}
+		joinValue := reflect.New(joinTable.ModelType).Interface()
+		err := db.Table(joinTable.Table).Migrator().CreateTable(joinValue)
+		if err != nil {
+			return nil, err
+		}
+		created[joinTable.Table] = true
+	}
+
+	return recorder.statements, nil
+}
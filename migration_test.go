@@ -0,0 +1,128 @@
+package golang_gorm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+var testMigrations = fstest.MapFS{
+	"0001_create_notes.up.sql":   {Data: []byte("CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT);")},
+	"0001_create_notes.down.sql": {Data: []byte("DROP TABLE notes;")},
+	"0002_create_tags.up.sql": {Data: []byte(`-- tags belong to notes
+CREATE TABLE tags (id INTEGER PRIMARY KEY, name TEXT);
+INSERT INTO tags (name) VALUES ('a;b');`)},
+	"0002_create_tags.down.sql": {Data: []byte("DROP TABLE tags;")},
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	runner, err := NewDefaultMigrationRunner(newTestDB(t))
+	assert.Nil(t, err)
+
+	statuses, err := runner.Status()
+	assert.Nil(t, err)
+	assert.Equal(t, 17, len(statuses))
+	for i, status := range statuses {
+		assert.Equal(t, i+1, status.Version)
+		assert.False(t, status.Applied)
+	}
+}
+
+func TestMigrationReadOnly(t *testing.T) {
+	db := newTestDB(t)
+	runner, err := NewReadOnlyMigrationRunner(db, testMigrations)
+	assert.Nil(t, err)
+
+	pending, err := runner.Pending()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(pending))
+	_, err = runner.Up()
+	assert.Equal(t, ErrReadOnlyRunner, err)
+	assert.False(t, db.Migrator().HasTable(&SchemaMigration{}))
+	assert.False(t, db.Migrator().HasTable("notes"))
+
+	writer, err := NewMigrationRunner(db, testMigrations)
+	assert.Nil(t, err)
+	_, err = writer.Up()
+	assert.Nil(t, err)
+	latest, err := runner.Latest(1)
+	assert.Nil(t, err)
+	assert.Equal(t, "create_tags", latest[0].Name)
+}
+
+func TestMigrationUpDownRedo(t *testing.T) {
+	db := newTestDB(t)
+	runner, err := NewMigrationRunner(db, testMigrations)
+	assert.Nil(t, err)
+
+	applied, err := runner.Up()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(applied))
+	assert.True(t, db.Migrator().HasTable("tags"))
+
+	var name string
+	err = db.Raw("select name from tags").Scan(&name).Error
+	assert.Nil(t, err)
+	assert.Equal(t, "a;b", name)
+
+	applied, err = runner.Up()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(applied))
+
+	redone, err := runner.Redo()
+	assert.Nil(t, err)
+	assert.Equal(t, "create_tags", redone.Name)
+
+	reverted, err := runner.Down(1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(reverted))
+	assert.False(t, db.Migrator().HasTable("tags"))
+	assert.True(t, db.Migrator().HasTable("notes"))
+
+	statuses, err := runner.Status()
+	assert.Nil(t, err)
+	assert.True(t, statuses[0].Applied)
+	assert.False(t, statuses[1].Applied)
+}
+
+func TestMigrationMissingDown(t *testing.T) {
+	_, err := LoadMigrations(fstest.MapFS{
+		"0001_create_notes.up.sql": {Data: []byte("CREATE TABLE notes (id INTEGER);")},
+	})
+	assert.NotNil(t, err)
+}
+
+func TestModelSQL(t *testing.T) {
+	statements, err := ModelSQL(mysql.New(mysql.Config{
+		DSN:                       "root:123@tcp(localhost:3306)/golang_gorm?parseTime=true",
+		SkipInitializeWithVersion: true,
+	}), Models()...)
+	assert.Nil(t, err)
+	assert.Equal(t, len(Models())+1, len(statements))
+	assert.Contains(t, statements[0], "CREATE TABLE `users`")
+	assert.Contains(t, statements[len(statements)-1], "CREATE TABLE `user_like_product`")
+	for _, statement := range statements {
+		if strings.HasPrefix(statement, "CREATE TABLE `wallets`") {
+			assert.Contains(t, statement, "`user_id` varchar(100)")
+		}
+	}
+}
+
+// MySQL cannot index or reference a TEXT column, so newTestDB's AutoMigrate
+// fails there when a key column is left without a size.
+func TestModelSQLKeysAreSized(t *testing.T) {
+	statements, err := ModelSQL(mysql.New(mysql.Config{
+		DSN:                       "root:123@tcp(localhost:3306)/golang_gorm?parseTime=true",
+		SkipInitializeWithVersion: true,
+	}), Models()...)
+	assert.Nil(t, err)
+	text := regexp.MustCompile("`(\\w+)` longtext")
+	for _, statement := range statements {
+		for _, match := range text.FindAllStringSubmatch(statement, -1) {
+			assert.Equal(t, 1, strings.Count(statement, "`"+match[1]+"`"), statement)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+CREATE TABLE users
+(
+    id          VARCHAR(100) NOT NULL,
+    password    VARCHAR(100) NOT NULL,
+    first_name  VARCHAR(100) NOT NULL,
+    middle_name VARCHAR(100) NULL,
+    last_name   VARCHAR(100) NULL,
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+) ENGINE = InnoDB;
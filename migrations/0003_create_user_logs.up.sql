@@ -0,0 +1,9 @@
+CREATE TABLE user_logs
+(
+    id         INT          NOT NULL AUTO_INCREMENT,
+    user_id    VARCHAR(100) NOT NULL,
+    action     VARCHAR(100) NOT NULL,
+    created_at BIGINT       NOT NULL,
+    updated_at BIGINT       NOT NULL,
+    PRIMARY KEY (id)
+) ENGINE = InnoDB;
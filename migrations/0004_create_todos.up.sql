@@ -0,0 +1,12 @@
+CREATE TABLE todos
+(
+    id          BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id     VARCHAR(100)    NOT NULL,
+    title       VARCHAR(100)    NOT NULL,
+    description TEXT            NULL,
+    created_at  TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at  TIMESTAMP       NULL,
+    PRIMARY KEY (id),
+    INDEX idx_todos_deleted_at (deleted_at)
+) ENGINE = InnoDB;
@@ -0,0 +1,10 @@
+CREATE TABLE wallets
+(
+    id         VARCHAR(100) NOT NULL,
+    user_id    VARCHAR(100) NULL,
+    balance    BIGINT       NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users_wallet FOREIGN KEY (user_id) REFERENCES users (id)
+) ENGINE = InnoDB;
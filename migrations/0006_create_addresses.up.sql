@@ -0,0 +1,10 @@
+CREATE TABLE addresses
+(
+    id         BIGINT       NOT NULL AUTO_INCREMENT,
+    user_id    VARCHAR(100) NOT NULL,
+    address    VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users_addresses FOREIGN KEY (user_id) REFERENCES users (id)
+) ENGINE = InnoDB;
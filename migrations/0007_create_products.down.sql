@@ -0,0 +1,3 @@
+DROP TABLE user_like_product;
+
+DROP TABLE products;
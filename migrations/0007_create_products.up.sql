@@ -0,0 +1,18 @@
+CREATE TABLE products
+(
+    id         VARCHAR(100) NOT NULL,
+    name       VARCHAR(100) NOT NULL,
+    price      BIGINT       NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+) ENGINE = InnoDB;
+
+CREATE TABLE user_like_product
+(
+    user_id    VARCHAR(100) NOT NULL,
+    product_id VARCHAR(100) NOT NULL,
+    PRIMARY KEY (user_id, product_id),
+    CONSTRAINT fk_user_like_product_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_user_like_product_product FOREIGN KEY (product_id) REFERENCES products (id)
+) ENGINE = InnoDB;
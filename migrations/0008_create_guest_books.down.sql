@@ -0,0 +1 @@
+DROP TABLE guest_books;
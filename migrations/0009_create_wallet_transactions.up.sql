@@ -0,0 +1,13 @@
+CREATE TABLE wallet_transactions
+(
+    id          BIGINT       NOT NULL AUTO_INCREMENT,
+    transfer_id VARCHAR(100) NOT NULL,
+    wallet_id   VARCHAR(100) NOT NULL,
+    type        VARCHAR(10)  NOT NULL,
+    amount      BIGINT       NOT NULL,
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_wallet_transactions_transfer_id (transfer_id),
+    INDEX idx_wallet_transactions_wallet_id (wallet_id),
+    CONSTRAINT fk_wallet_transactions_wallet FOREIGN KEY (wallet_id) REFERENCES wallets (id)
+) ENGINE = InnoDB;
@@ -0,0 +1,14 @@
+CREATE TABLE wallet_operations
+(
+    id              BIGINT       NOT NULL AUTO_INCREMENT,
+    idempotency_key VARCHAR(100) NOT NULL,
+    wallet_id       VARCHAR(100) NOT NULL,
+    type            VARCHAR(10)  NOT NULL,
+    amount          BIGINT       NOT NULL,
+    balance_after   BIGINT       NOT NULL,
+    created_at      TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_wallet_operations_idempotency_key (idempotency_key),
+    INDEX idx_wallet_operations_wallet_id (wallet_id),
+    CONSTRAINT fk_wallet_operations_wallet FOREIGN KEY (wallet_id) REFERENCES wallets (id)
+) ENGINE = InnoDB;
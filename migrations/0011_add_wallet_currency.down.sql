@@ -0,0 +1,8 @@
+DROP TABLE exchange_rates;
+
+ALTER TABLE wallet_transactions
+    DROP COLUMN currency;
+
+ALTER TABLE wallets
+    DROP INDEX idx_wallets_user_id_currency,
+    DROP COLUMN currency;
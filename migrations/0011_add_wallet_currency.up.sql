@@ -0,0 +1,18 @@
+ALTER TABLE wallets
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'IDR' AFTER balance,
+    ADD INDEX idx_wallets_user_id_currency (user_id, currency);
+
+ALTER TABLE wallet_transactions
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'IDR' AFTER amount;
+
+CREATE TABLE exchange_rates
+(
+    id             BIGINT          NOT NULL AUTO_INCREMENT,
+    base_currency  CHAR(3)         NOT NULL,
+    quote_currency CHAR(3)         NOT NULL,
+    rate           DECIMAL(24, 12) NOT NULL,
+    effective_at   TIMESTAMP       NOT NULL,
+    created_at     TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_exchange_rates_pair (base_currency, quote_currency, effective_at)
+) ENGINE = InnoDB;
@@ -0,0 +1,4 @@
+DELETE FROM wallet_transactions WHERE type = 'opening';
+
+ALTER TABLE wallet_transactions
+    DROP INDEX idx_wallet_transactions_history;
@@ -0,0 +1,15 @@
+ALTER TABLE wallet_transactions
+    ADD INDEX idx_wallet_transactions_history (wallet_id, created_at);
+
+-- Give every existing wallet an opening movement so its history adds up to
+-- the current balance.
+INSERT INTO wallet_transactions (transfer_id, wallet_id, type, amount, currency, created_at)
+SELECT CONCAT('Opening-', opening.id), opening.id, 'opening', opening.amount, opening.currency, opening.created_at
+FROM (SELECT wallets.id,
+             wallets.currency,
+             wallets.created_at,
+             wallets.balance - COALESCE((SELECT SUM(CASE WHEN type = 'debit' THEN -amount ELSE amount END)
+                                         FROM wallet_transactions
+                                         WHERE wallet_id = wallets.id), 0) AS amount
+      FROM wallets) opening
+WHERE opening.amount <> 0;
@@ -0,0 +1,5 @@
+ALTER TABLE user_logs
+    DROP INDEX idx_user_logs_entity,
+    DROP COLUMN payload,
+    DROP COLUMN entity_id,
+    DROP COLUMN entity_type;
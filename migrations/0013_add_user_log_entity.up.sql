@@ -0,0 +1,5 @@
+ALTER TABLE user_logs
+    ADD COLUMN entity_type VARCHAR(100) NULL AFTER action,
+    ADD COLUMN entity_id   VARCHAR(100) NULL AFTER entity_type,
+    ADD COLUMN payload     TEXT         NULL AFTER entity_id,
+    ADD INDEX idx_user_logs_entity (entity_type, entity_id);
@@ -0,0 +1,4 @@
+ALTER TABLE user_logs
+    DROP INDEX idx_user_logs_created_at,
+    DROP INDEX idx_user_logs_user,
+    MODIFY payload TEXT NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE user_logs
+    MODIFY payload JSON NULL,
+    ADD INDEX idx_user_logs_user (user_id, created_at),
+    ADD INDEX idx_user_logs_created_at (created_at);
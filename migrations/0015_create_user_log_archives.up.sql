@@ -0,0 +1,13 @@
+CREATE TABLE user_log_archives
+(
+    id           BIGINT   NOT NULL AUTO_INCREMENT,
+    first_id     INT      NOT NULL,
+    last_id      INT      NOT NULL,
+    created_from BIGINT   NOT NULL,
+    created_to   BIGINT   NOT NULL,
+    entries      INT      NOT NULL,
+    data         LONGBLOB NOT NULL,
+    archived_at  BIGINT   NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_user_log_archives_created (created_from, created_to)
+) ENGINE = InnoDB;
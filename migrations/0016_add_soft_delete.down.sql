@@ -0,0 +1,15 @@
+ALTER TABLE products
+    DROP INDEX idx_products_deleted_at,
+    DROP COLUMN deleted_at;
+
+ALTER TABLE addresses
+    DROP INDEX idx_addresses_deleted_at,
+    DROP COLUMN deleted_at;
+
+ALTER TABLE wallets
+    DROP INDEX idx_wallets_deleted_at,
+    DROP COLUMN deleted_at;
+
+ALTER TABLE users
+    DROP INDEX idx_users_deleted_at,
+    DROP COLUMN deleted_at;
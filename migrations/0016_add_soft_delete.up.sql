@@ -0,0 +1,15 @@
+ALTER TABLE users
+    ADD COLUMN deleted_at TIMESTAMP(3) NULL AFTER updated_at,
+    ADD INDEX idx_users_deleted_at (deleted_at);
+
+ALTER TABLE wallets
+    ADD COLUMN deleted_at TIMESTAMP(3) NULL AFTER updated_at,
+    ADD INDEX idx_wallets_deleted_at (deleted_at);
+
+ALTER TABLE addresses
+    ADD COLUMN deleted_at TIMESTAMP(3) NULL AFTER updated_at,
+    ADD INDEX idx_addresses_deleted_at (deleted_at);
+
+ALTER TABLE products
+    ADD COLUMN deleted_at TIMESTAMP(3) NULL AFTER updated_at,
+    ADD INDEX idx_products_deleted_at (deleted_at);
@@ -0,0 +1,3 @@
+ALTER TABLE wallets
+    DROP INDEX idx_wallets_user_id_currency,
+    ADD UNIQUE INDEX idx_wallets_user_id_currency (user_id, currency);
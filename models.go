@@ -0,0 +1,19 @@
+package golang_gorm
+
+// Models lists every model of the package in dependency order, parents
+// before the tables that reference them.
+func Models() []interface{} {
+	return []interface{}{
+		&User{},
+		&Wallet{},
+		&Address{},
+		&Product{},
+		&Todo{},
+		&UserLog{},
+		&GuestBook{},
+		&WalletTransaction{},
+		&WalletOperation{},
+		&ExchangeRate{},
+		&UserLogArchive{},
+	}
+}
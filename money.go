@@ -0,0 +1,163 @@
+package golang_gorm
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultCurrency is given to wallets created without a currency.
+const DefaultCurrency = "IDR"
+
+// Currencies maps the supported ISO 4217 codes to their number of minor
+// units. Amounts are always stored in minor units (sen, cents).
+var Currencies = map[string]int{
+	"IDR": 2,
+	"USD": 2,
+}
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyMismatch = errors.New("currencies do not match")
+	ErrNoExchangeRate   = errors.New("no exchange rate")
+)
+
+// Money is an amount in minor units of a currency. Wallets keep the two parts
+// in columns of their own; as a driver.Value, for a query or a column that
+// needs both in one, Money is the text written by String, like "USD 12.34".
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func (m Money) String() string {
+	units, ok := Currencies[m.Currency]
+	if !ok || units == 0 {
+		return fmt.Sprintf("%s %d", m.Currency, m.Amount)
+	}
+
+	sign, amount := "", m.Amount
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	scale := int64(1)
+	for i := 0; i < units; i++ {
+		scale *= 10
+	}
+	return fmt.Sprintf("%s %s%d.%0*d", m.Currency, sign, amount/scale, units, amount%scale)
+}
+
+// ParseMoney reads the format written by Money.String.
+func ParseMoney(text string) (Money, error) {
+	code, amount, ok := strings.Cut(strings.TrimSpace(text), " ")
+	if !ok {
+		return Money{}, fmt.Errorf("invalid money %q", text)
+	}
+	units, ok := Currencies[code]
+	if !ok {
+		return Money{}, fmt.Errorf("%w %s", ErrUnknownCurrency, code)
+	}
+
+	whole, fraction, _ := strings.Cut(amount, ".")
+	if len(fraction) != units {
+		return Money{}, fmt.Errorf("invalid money %q: %s needs %d decimals", text, code, units)
+	}
+	minor, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid money %q: %w", text, err)
+	}
+	return Money{Amount: minor, Currency: code}, nil
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+func (m *Money) Scan(src interface{}) error {
+	var text string
+	switch value := src.(type) {
+	case string:
+		text = value
+	case []byte:
+		text = string(value)
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+
+	parsed, err := ParseMoney(text)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+// ConvertMoney converts m into currency with the most recent rate stored in
+// exchange_rates, using the inverse pair when only that one exists. The
+// result is rounded down so a conversion never creates money.
+func ConvertMoney(ctx context.Context, db *gorm.DB, m Money, currency string, at time.Time) (Money, error) {
+	if m.Currency == currency {
+		return m, nil
+	}
+	fromUnits, ok := Currencies[m.Currency]
+	if !ok {
+		return Money{}, fmt.Errorf("%w %s", ErrUnknownCurrency, m.Currency)
+	}
+	toUnits, ok := Currencies[currency]
+	if !ok {
+		return Money{}, fmt.Errorf("%w %s", ErrUnknownCurrency, currency)
+	}
+
+	rate, err := exchangeRate(ctx, db, m.Currency, currency, at)
+	if err != nil {
+		return Money{}, err
+	}
+
+	converted := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Amount), rate)
+	converted.Mul(converted, new(big.Rat).SetFrac(pow10(toUnits), pow10(fromUnits)))
+	amount := new(big.Int).Quo(converted.Num(), converted.Denom())
+	if !amount.IsInt64() {
+		return Money{}, fmt.Errorf("converting %s to %s overflows", m, currency)
+	}
+	return Money{Amount: amount.Int64(), Currency: currency}, nil
+}
+
+func exchangeRate(ctx context.Context, db *gorm.DB, from, to string, at time.Time) (*big.Rat, error) {
+	var rates []ExchangeRate
+	err := db.WithContext(ctx).
+		Where("base_currency = ? AND quote_currency = ? AND effective_at <= ?", from, to, at).
+		Or("base_currency = ? AND quote_currency = ? AND effective_at <= ?", to, from, at).
+		Order("effective_at desc").Limit(1).Find(&rates).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("%w from %s to %s", ErrNoExchangeRate, from, to)
+	}
+
+	rate, ok := new(big.Rat).SetString(rates[0].Rate)
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid exchange rate %q for %s/%s", rates[0].Rate, rates[0].BaseCurrency, rates[0].QuoteCurrency)
+	}
+	if rates[0].BaseCurrency != from {
+		rate.Inv(rate)
+	}
+	return rate, nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
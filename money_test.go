@@ -0,0 +1,129 @@
+package golang_gorm
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMoneyFormat(t *testing.T) {
+	money := Money{Amount: -1234, Currency: "USD"}
+	assert.Equal(t, "USD -12.34", money.String())
+
+	parsed, err := ParseMoney("USD -12.34")
+	assert.Nil(t, err)
+	assert.Equal(t, money, parsed)
+
+	_, err = ParseMoney("EUR 1.00")
+	assert.True(t, errors.Is(err, ErrUnknownCurrency))
+	_, err = ParseMoney("USD 1.5")
+	assert.NotNil(t, err)
+
+	_, err = money.Add(Money{Amount: 1, Currency: "IDR"})
+	assert.True(t, errors.Is(err, ErrCurrencyMismatch))
+}
+
+func TestMoneyScan(t *testing.T) {
+	db := newTestDB(t)
+
+	var scanned Money
+	err := db.Raw("select ?", Money{Amount: 150000, Currency: "IDR"}).Scan(&scanned).Error
+	assert.Nil(t, err)
+	assert.Equal(t, Money{Amount: 150000, Currency: "IDR"}, scanned)
+}
+
+func TestConvertMoney(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	_, err := ConvertMoney(ctx, db, Money{Amount: 100, Currency: "USD"}, "IDR", now)
+	assert.True(t, errors.Is(err, ErrNoExchangeRate))
+
+	err = db.Create(&[]ExchangeRate{
+		{BaseCurrency: "USD", QuoteCurrency: "IDR", Rate: "15000", EffectiveAt: now.Add(-48 * time.Hour)},
+		{BaseCurrency: "USD", QuoteCurrency: "IDR", Rate: "16000", EffectiveAt: now.Add(-time.Hour)},
+		{BaseCurrency: "USD", QuoteCurrency: "IDR", Rate: "17000", EffectiveAt: now.Add(time.Hour)},
+	}).Error
+	assert.Nil(t, err)
+
+	converted, err := ConvertMoney(ctx, db, Money{Amount: 100, Currency: "USD"}, "IDR", now)
+	assert.Nil(t, err)
+	assert.Equal(t, Money{Amount: 1600000, Currency: "IDR"}, converted)
+
+	converted, err = ConvertMoney(ctx, db, Money{Amount: 100, Currency: "USD"}, "IDR", now.Add(-24*time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, Money{Amount: 1500000, Currency: "IDR"}, converted)
+
+	// Only USD/IDR is stored, so IDR to USD uses its inverse and rounds down.
+	converted, err = ConvertMoney(ctx, db, Money{Amount: 1599999, Currency: "IDR"}, "USD", now)
+	assert.Nil(t, err)
+	assert.Equal(t, Money{Amount: 99, Currency: "USD"}, converted)
+}
+
+func TestOpenWallet(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+	service := NewWalletService(db)
+	ctx := context.Background()
+
+	usd, err := service.OpenWallet(ctx, "2", "USD")
+	assert.Nil(t, err)
+	assert.Equal(t, "USD", usd.Currency)
+	assert.Equal(t, int64(0), usd.Balance)
+
+	again, err := service.OpenWallet(ctx, "2", "USD")
+	assert.Nil(t, err)
+	assert.Equal(t, usd.ID, again.ID)
+
+	idr, err := service.OpenWallet(ctx, "2", "IDR")
+	assert.Nil(t, err)
+	assert.Equal(t, "01", idr.ID)
+
+	_, err = service.OpenWallet(ctx, "2", "EUR")
+	assert.True(t, errors.Is(err, ErrUnknownCurrency))
+
+	// One wallet per currency: a second is refused, a deleted one reopened.
+	err = Classify(db.Create(&Wallet{UserId: "2", Currency: "USD"}).Error)
+	assert.True(t, errors.Is(err, ErrConflict))
+	err = db.Delete(&usd).Error
+	assert.Nil(t, err)
+	again, err = service.OpenWallet(ctx, "2", "USD")
+	assert.Nil(t, err)
+	assert.Equal(t, usd.ID, again.ID)
+	assert.False(t, again.DeletedAt.Valid)
+
+	wallets, err := service.Wallets(ctx, "2")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(wallets))
+	assert.Equal(t, "IDR", wallets[0].Currency)
+	assert.Equal(t, "USD", wallets[1].Currency)
+}
+
+func TestTransferBetweenCurrencies(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+	ctx := context.Background()
+
+	usd, err := NewWalletService(db).OpenWallet(ctx, "2", "USD")
+	assert.Nil(t, err)
+
+	service := NewTransferService(db)
+	_, err = service.Transfer(ctx, "1", usd.ID, 1600000)
+	assert.True(t, errors.Is(err, ErrNoExchangeRate))
+
+	err = db.Create(&ExchangeRate{BaseCurrency: "USD", QuoteCurrency: "IDR", Rate: "16000", EffectiveAt: time.Now().Add(-time.Hour)}).Error
+	assert.Nil(t, err)
+
+	transfer, err := service.Transfer(ctx, "1", usd.ID, 1600000)
+	assert.Nil(t, err)
+	assert.Equal(t, Money{Amount: 1600000, Currency: "IDR"}, transfer.Sent)
+	assert.Equal(t, Money{Amount: 100, Currency: "USD"}, transfer.Received)
+	assert.Equal(t, int64(998400000), walletBalance(t, db, "1"))
+	assert.Equal(t, int64(100), walletBalance(t, db, usd.ID))
+	assert.Equal(t, "IDR", transfer.Debit.Currency)
+	assert.Equal(t, "USD", transfer.Credit.Currency)
+
+	_, err = service.Transfer(ctx, "1", usd.ID, 100)
+	assert.True(t, errors.Is(err, ErrInvalidAmount))
+}
@@ -0,0 +1,85 @@
+package golang_gorm
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+const DefaultPageSize = 20
+
+// MaxPageSize caps PageRequest.Size so one request cannot load a whole table.
+var MaxPageSize = 100
+
+// PageRequest asks for page number Page, counting from 1, of Size items. Out of
+// range values are corrected rather than rejected: a missing page is the
+// first, a missing size is DefaultPageSize and a larger one MaxPageSize.
+type PageRequest struct {
+	Page int
+	Size int
+}
+
+func (r PageRequest) normalize() PageRequest {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+	if r.Size < 1 {
+		r.Size = DefaultPageSize
+	}
+	if r.Size > MaxPageSize {
+		r.Size = MaxPageSize
+	}
+	return r
+}
+
+// Page is one page of an offset listing with what a pager needs to render.
+type Page[T any] struct {
+	Items      []T
+	Page       int
+	Size       int
+	TotalItems int64
+	TotalPages int
+}
+
+func (p Page[T]) HasNext() bool {
+	return p.Page < p.TotalPages
+}
+
+func (p Page[T]) HasPrev() bool {
+	return p.Page > 1
+}
+
+// Paginate is a Scope selecting the rows of one page.
+func Paginate(request PageRequest) Scope {
+	request = request.normalize()
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Offset((request.Page - 1) * request.Size).Limit(request.Size)
+	}
+}
+
+// FindPage counts the rows of T matching scopes and loads the requested page
+// of them. Prefer FindKeyset for deep pages of large tables; the offset still
+// makes the database walk every skipped row.
+func FindPage[T any](ctx context.Context, db *gorm.DB, request PageRequest, scopes ...Scope) (Page[T], error) {
+	request = request.normalize()
+	page := Page[T]{Page: request.Page, Size: request.Size}
+
+	query := FromContext(ctx, db).Model(new(T))
+	for _, scope := range scopes {
+		query = query.Scopes(scope)
+	}
+
+	err := query.Session(&gorm.Session{}).Count(&page.TotalItems).Error
+	if err != nil {
+		return Page[T]{}, Classify(err)
+	}
+	page.TotalPages = int((page.TotalItems + int64(page.Size) - 1) / int64(page.Size))
+
+	page.Items = []T{}
+	if int64((page.Page-1)*page.Size) < page.TotalItems {
+		err = query.Scopes(Paginate(request)).Find(&page.Items).Error
+		if err != nil {
+			return Page[T]{}, Classify(err)
+		}
+	}
+	return page, nil
+}
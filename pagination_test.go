@@ -0,0 +1,45 @@
+package golang_gorm
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFindPage(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+	ctx := context.Background()
+	filters := []Scope{Where("first_name LIKE ?", "User%"), OrderBy("id asc")}
+
+	page, err := FindPage[User](ctx, db, PageRequest{Page: 4, Size: 5}, filters...)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(18), page.TotalItems)
+	assert.Equal(t, 4, page.TotalPages)
+	assert.Equal(t, 4, page.Page)
+	assert.Equal(t, 3, len(page.Items))
+	assert.False(t, page.HasNext())
+	assert.True(t, page.HasPrev())
+
+	var offset []User
+	err = db.Scopes(filters[0], filters[1], Paginate(PageRequest{Page: 4, Size: 5})).Find(&offset).Error
+	assert.Nil(t, err)
+	assert.Equal(t, userIDs(offset), userIDs(page.Items))
+
+	page, err = FindPage[User](ctx, db, PageRequest{Page: 9, Size: 5}, filters...)
+	assert.Nil(t, err)
+	assert.Empty(t, page.Items)
+	assert.Equal(t, 4, page.TotalPages)
+
+	page, err = FindPage[User](ctx, db, PageRequest{Size: 1000})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, page.Page)
+	assert.Equal(t, MaxPageSize, page.Size)
+	assert.Equal(t, 19, len(page.Items))
+	assert.False(t, page.HasPrev())
+
+	wallets, err := NewRepository[Wallet, string](db).Paginate(ctx, PageRequest{}, SultanWalletBalance)
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultPageSize, wallets.Size)
+	assert.Equal(t, int64(4), wallets.TotalItems)
+	assert.Equal(t, 1, wallets.TotalPages)
+}
@@ -0,0 +1,126 @@
+package golang_gorm
+
+import (
+	"context"
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// PasswordCost is the bcrypt cost used for new hashes. Raising it makes
+// VerifyPassword upgrade older hashes the next time their owner logs in.
+var PasswordCost = bcrypt.DefaultCost
+
+var ErrPasswordMismatch = errors.New("password does not match")
+
+const redacted = "[REDACTED]"
+
+// Password holds a bcrypt hash once the user has been saved. It prints as
+// [REDACTED] and is masked in SQL logged through RedactPasswords.
+type Password string
+
+func (p Password) String() string {
+	return redacted
+}
+
+func (p Password) hashed() bool {
+	_, err := bcrypt.Cost([]byte(p))
+	return err == nil
+}
+
+func (p Password) hash() (Password, error) {
+	if p == "" || p.hashed() {
+		return p, nil
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(p), PasswordCost)
+	if err != nil {
+		return "", err
+	}
+	return Password(hash), nil
+}
+
+func (p Password) needsRehash() bool {
+	cost, err := bcrypt.Cost([]byte(p))
+	return err == nil && cost != PasswordCost
+}
+
+// hashPasswordColumn hashes a plaintext password passed to Update or Updates
+// as a map, where it never reaches the model's Password field.
+func hashPasswordColumn(dest map[string]interface{}) error {
+	for _, key := range []string{"password", "Password"} {
+		var password Password
+		switch value := dest[key].(type) {
+		case string:
+			password = Password(value)
+		case Password:
+			password = value
+		default:
+			continue
+		}
+
+		hashed, err := password.hash()
+		if err != nil {
+			return err
+		}
+		dest[key] = hashed
+	}
+	return nil
+}
+
+// VerifyPassword checks password against the stored hash. When the hash was
+// made with a cost other than PasswordCost and db is not nil, the user's hash
+// is replaced with a fresh one.
+func (u *User) VerifyPassword(db *gorm.DB, password string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) || errors.Is(err, bcrypt.ErrHashTooShort) {
+		return ErrPasswordMismatch
+	}
+	if err != nil {
+		return err
+	}
+
+	if db == nil || !u.Password.needsRehash() {
+		return nil
+	}
+	hashed, err := Password(password).hash()
+	if err != nil {
+		return err
+	}
+	err = db.Model(u).UpdateColumn("password", hashed).Error
+	if err != nil {
+		return err
+	}
+	u.Password = hashed
+	return nil
+}
+
+type redactingLogger struct {
+	logger.Interface
+}
+
+// RedactPasswords wraps a GORM logger so Password values show up as
+// [REDACTED] in the SQL it prints.
+func RedactPasswords(l logger.Interface) logger.Interface {
+	return redactingLogger{Interface: l}
+}
+
+func (l redactingLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return redactingLogger{Interface: l.Interface.LogMode(level)}
+}
+
+func (l redactingLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	filtered := make([]interface{}, len(params))
+	for i, param := range params {
+		if _, ok := param.(Password); ok {
+			filtered[i] = redacted
+		} else {
+			filtered[i] = param
+		}
+	}
+
+	if filter, ok := l.Interface.(gorm.ParamsFilter); ok {
+		return filter.ParamsFilter(ctx, sql, filtered...)
+	}
+	return sql, filtered
+}
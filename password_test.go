@@ -0,0 +1,83 @@
+package golang_gorm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"log"
+	"testing"
+)
+
+func TestPasswordHashedOnCreate(t *testing.T) {
+	db := newTestDB(t)
+
+	user := User{ID: "1", Password: "rahasia", Name: Name{FirstName: "Brian"}}
+	err := db.Create(&user).Error
+	assert.Nil(t, err)
+	assert.NotEqual(t, Password("rahasia"), user.Password)
+
+	var stored User
+	err = db.Take(&stored, "id = ?", "1").Error
+	assert.Nil(t, err)
+	assert.Equal(t, user.Password, stored.Password)
+	assert.Nil(t, stored.VerifyPassword(nil, "rahasia"))
+	assert.Equal(t, ErrPasswordMismatch, stored.VerifyPassword(nil, "salah"))
+
+	stored.Name.LastName = "Anashari"
+	err = db.Save(&stored).Error
+	assert.Nil(t, err)
+	assert.Equal(t, user.Password, stored.Password)
+}
+
+func TestPasswordRehash(t *testing.T) {
+	db := newTestDB(t, "users")
+
+	var user User
+	err := db.Take(&user, "id = ?", "1").Error
+	assert.Nil(t, err)
+	oldHash := user.Password
+
+	PasswordCost = bcrypt.MinCost + 1
+	defer func() {
+		PasswordCost = bcrypt.MinCost
+	}()
+
+	err = user.VerifyPassword(db, "rahasia")
+	assert.Nil(t, err)
+	assert.NotEqual(t, oldHash, user.Password)
+
+	var stored User
+	err = db.Take(&stored, "id = ?", "1").Error
+	assert.Nil(t, err)
+	assert.Equal(t, user.Password, stored.Password)
+	cost, err := bcrypt.Cost([]byte(stored.Password))
+	assert.Nil(t, err)
+	assert.Equal(t, bcrypt.MinCost+1, cost)
+}
+
+func TestPasswordNeverExposed(t *testing.T) {
+	var output bytes.Buffer
+	db := newTestDB(t).Session(&gorm.Session{Logger: RedactPasswords(logger.New(
+		log.New(&output, "", 0), logger.Config{LogLevel: logger.Info},
+	))})
+
+	user := User{ID: "1", Password: "rahasia", Name: Name{FirstName: "Brian"}}
+	err := db.Create(&user).Error
+	assert.Nil(t, err)
+	err = db.Model(&user).Update("password", "hidden").Error
+	assert.Nil(t, err)
+
+	assert.Contains(t, output.String(), "[REDACTED]")
+	assert.NotContains(t, output.String(), "rahasia")
+	assert.NotContains(t, output.String(), "hidden")
+	assert.NotContains(t, output.String(), "$2a$")
+
+	encoded, err := json.Marshal(user)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(encoded), "assword")
+	assert.NotContains(t, fmt.Sprint(user), "$2a$")
+}
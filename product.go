@@ -1,16 +1,27 @@
 package golang_gorm
 
-import "time"
+import (
+	"gorm.io/gorm"
+	"time"
+)
 
 type Product struct {
-	ID           string    `gorm:"primary_key;column:id"`
-	Name         string    `gorm:"column:name"`
-	Price        int64     `gorm:"column:price"`
-	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt    time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
-	LikedByUsers []User    `gorm:"many2many:user_like_product;foreignKey:id;joinForeignKey:product_id;references:id;joinReferences:user_id"`
+	ID           string         `gorm:"primary_key;column:id"`
+	Name         string         `gorm:"column:name"`
+	Price        int64          `gorm:"column:price"`
+	CreatedAt    time.Time      `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt    time.Time      `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	DeletedAt    gorm.DeletedAt `gorm:"column:deleted_at;index"`
+	LikedByUsers []User         `gorm:"many2many:user_like_product;foreignKey:id;joinForeignKey:product_id;references:id;joinReferences:user_id"`
 }
 
 func (p *Product) TableName() string {
 	return "products"
 }
+
+func (p *Product) BeforeCreate(db *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = "Product-" + DefaultIDGenerator.NewID()
+	}
+	return nil
+}
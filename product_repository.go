@@ -0,0 +1,39 @@
+package golang_gorm
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+type ProductRepository interface {
+	FindByID(ctx context.Context, id string) (Product, error)
+	// FindAll lists products; pass WithDeleted or OnlyDeleted to see deleted
+	// ones.
+	FindAll(ctx context.Context, scopes ...Scope) ([]Product, error)
+	// LikedBy returns the products the user likes.
+	LikedBy(ctx context.Context, userID string) ([]Product, error)
+	Create(ctx context.Context, product *Product) error
+	Update(ctx context.Context, product *Product) error
+	// Delete soft deletes the product, keeping the users' likes for Restore.
+	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
+}
+
+type productRepository struct {
+	*Repository[Product, string]
+}
+
+func NewProductRepository(db *gorm.DB) ProductRepository {
+	return &productRepository{Repository: NewRepository[Product, string](db)}
+}
+
+func (r *productRepository) LikedBy(ctx context.Context, userID string) ([]Product, error) {
+	return r.FindAll(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Joins("join user_like_product on user_like_product.product_id = products.id").
+			Where("user_like_product.user_id = ?", userID)
+	}, OrderBy("products.id asc"))
+}
+
+func (r *productRepository) Update(ctx context.Context, product *Product) error {
+	return r.Save(ctx, product)
+}
@@ -0,0 +1,244 @@
+package golang_gorm
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const DefaultPurgeBatchSize = 500
+
+// PurgeReport is what a purge did, or in a dry run would do, to one table.
+// Blocked counts the rows past the retention window that are kept because
+// rows which are not being purged still point at them, such as a wallet with
+// ledger movements.
+type PurgeReport struct {
+	Table   string
+	Purged  int64
+	Blocked int64
+}
+
+// Purger permanently deletes soft deleted rows whose DeletedAt is older than
+// Retention. Rows in many-to-many join tables that point at a purged row are
+// deleted with it; any other row pointing at it keeps it, unless that row is
+// purged in the same run.
+type Purger struct {
+	Retention time.Duration
+	BatchSize int
+	// DryRun only counts the rows that would be purged.
+	DryRun bool
+	// Pause is slept between batches to leave room for other work.
+	Pause time.Duration
+
+	db      *gorm.DB
+	targets []*schema.Schema
+	schemas []*schema.Schema
+	now     func() time.Time
+}
+
+// purgeReference is a column of another table that holds a purged table's
+// primary key.
+type purgeReference struct {
+	schema *schema.Schema
+	table  string
+	column string
+	join   bool
+}
+
+// NewPurger purges the given models, or every soft deletable one of Models
+// when none are given. Models are listed parents first, as in Models, and
+// purged children first.
+func NewPurger(db *gorm.DB, retention time.Duration, models ...interface{}) (*Purger, error) {
+	purger := &Purger{Retention: retention, BatchSize: DefaultPurgeBatchSize, db: db, now: time.Now}
+	for _, model := range Models() {
+		sch, err := parseSchema(db, model)
+		if err != nil {
+			return nil, err
+		}
+		purger.schemas = append(purger.schemas, sch)
+		if len(models) == 0 && softDeleteField(sch) != nil {
+			purger.targets = append(purger.targets, sch)
+		}
+	}
+	for _, model := range models {
+		sch, err := parseSchema(db, model)
+		if err != nil {
+			return nil, err
+		}
+		if softDeleteField(sch) == nil {
+			return nil, ErrSoftDeleteUnsupported
+		}
+		purger.targets = append(purger.targets, sch)
+	}
+	return purger, nil
+}
+
+// Run purges every target table and reports on each, children first.
+func (p *Purger) Run(ctx context.Context) ([]PurgeReport, error) {
+	if p.Retention <= 0 {
+		return nil, errors.New("purge needs a positive Retention")
+	}
+	cutoff := p.now().Add(-p.Retention)
+
+	var reports []PurgeReport
+	for i := len(p.targets) - 1; i >= 0; i-- {
+		report, err := p.purge(ctx, p.targets[i], cutoff)
+		reports = append(reports, report)
+		if err != nil {
+			return reports, err
+		}
+	}
+	return reports, nil
+}
+
+func (p *Purger) purge(ctx context.Context, sch *schema.Schema, cutoff time.Time) (report PurgeReport, err error) {
+	report.Table = sch.Table
+	db := FromContext(ctx, p.db)
+	purgeable := p.purgeable(sch, cutoff, map[string]bool{})
+
+	var expired int64
+	err = db.Unscoped().Table(sch.Table).Where(p.expired(sch), cutoff).Count(&expired).Error
+	if err != nil {
+		return report, Classify(err)
+	}
+	defer func() {
+		report.Blocked = expired - report.Purged
+	}()
+
+	if p.DryRun {
+		err = db.Unscoped().Table(sch.Table).Where(purgeable).Count(&report.Purged).Error
+		return report, Classify(err)
+	}
+
+	size := max(p.BatchSize, 1)
+	primary := sch.PrioritizedPrimaryField
+	for {
+		var ids []interface{}
+		err = db.Unscoped().Table(sch.Table).Where(purgeable).
+			Order(clause.OrderByColumn{Column: clause.Column{Name: primary.DBName}}).
+			Limit(size).Pluck(primary.DBName, &ids).Error
+		if err != nil {
+			return report, Classify(err)
+		}
+		if len(ids) == 0 {
+			return report, nil
+		}
+
+		var deleted int64
+		err = NewTxManager(p.db).WithinTx(ctx, func(ctx context.Context) error {
+			tx := FromContext(ctx, p.db)
+			for _, reference := range p.references(sch) {
+				if !reference.join {
+					continue
+				}
+				err := tx.Exec("DELETE FROM ? WHERE ? IN ?",
+					clause.Table{Name: reference.table}, clause.Column{Name: reference.column}, ids).Error
+				if err != nil {
+					return err
+				}
+			}
+			result := tx.Unscoped().Delete(reflect.New(sch.ModelType).Interface(),
+				clause.IN{Column: clause.PrimaryColumn, Values: ids})
+			deleted = result.RowsAffected
+			return result.Error
+		})
+		if err != nil {
+			return report, Classify(err)
+		}
+		report.Purged += deleted
+
+		if len(ids) < size {
+			return report, nil
+		}
+		if p.Pause > 0 {
+			if err := wait(ctx, p.Pause); err != nil {
+				return report, err
+			}
+		}
+	}
+}
+
+// expired is the condition for rows deleted before the cutoff, which is its
+// one variable.
+func (p *Purger) expired(sch *schema.Schema) string {
+	column := p.quote(sch.Table, softDeleteField(sch).DBName)
+	return column + " IS NOT NULL AND " + column + " < ?"
+}
+
+// purgeable is the condition for expired rows of sch that nothing left behind
+// points at. A soft deletable child only blocks its parent when it is not
+// purgeable itself; seen stops the recursion on cycles.
+func (p *Purger) purgeable(sch *schema.Schema, cutoff time.Time, seen map[string]bool) clause.Expr {
+	seen[sch.Table] = true
+	sql := []string{p.expired(sch)}
+	vars := []interface{}{cutoff}
+
+	primary := p.quote(sch.Table, sch.PrioritizedPrimaryField.DBName)
+	for _, reference := range p.references(sch) {
+		if reference.join {
+			continue
+		}
+		exists := "NOT EXISTS (SELECT 1 FROM " + p.quote(reference.table, "") +
+			" WHERE " + p.quote(reference.table, reference.column) + " = " + primary
+		if reference.schema != nil && softDeleteField(reference.schema) != nil && !seen[reference.table] {
+			child := p.purgeable(reference.schema, cutoff, seen)
+			exists += " AND NOT (" + child.SQL + ")"
+			vars = append(vars, child.Vars...)
+		}
+		sql = append(sql, exists+")")
+	}
+	delete(seen, sch.Table)
+	return clause.Expr{SQL: strings.Join(sql, " AND "), Vars: vars}
+}
+
+// references lists the columns that hold primary keys of sch: foreign keys
+// of its has-one, has-many and belongs-to associations, and its join tables.
+func (p *Purger) references(sch *schema.Schema) []purgeReference {
+	seen := map[string]bool{}
+	var references []purgeReference
+	add := func(reference purgeReference) {
+		key := reference.table + "." + reference.column
+		if !seen[key] {
+			seen[key] = true
+			references = append(references, reference)
+		}
+	}
+
+	for _, other := range p.schemas {
+		for _, relationship := range other.Relationships.Relations {
+			for _, ref := range relationship.References {
+				if ref.PrimaryKey == nil || ref.PrimaryKey.Schema.Table != sch.Table || ref.PrimaryKey.Schema == ref.ForeignKey.Schema {
+					continue
+				}
+				switch {
+				case relationship.JoinTable != nil:
+					add(purgeReference{table: relationship.JoinTable.Table, column: ref.ForeignKey.DBName, join: true})
+				case ref.ForeignKey.Schema.Table != sch.Table:
+					add(purgeReference{schema: ref.ForeignKey.Schema, table: ref.ForeignKey.Schema.Table, column: ref.ForeignKey.DBName})
+				}
+			}
+		}
+	}
+	return references
+}
+
+// quote quotes a table, or a column of it when column is not empty.
+func (p *Purger) quote(table, column string) string {
+	if column == "" {
+		return p.db.Statement.Quote(clause.Table{Name: table})
+	}
+	return p.db.Statement.Quote(clause.Column{Table: table, Name: column})
+}
+
+func parseSchema(db *gorm.DB, model interface{}) (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
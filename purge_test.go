@@ -0,0 +1,87 @@
+package golang_gorm
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func countAll(t *testing.T, db *gorm.DB, table string) int64 {
+	var count int64
+	err := db.Table(table).Count(&count).Error
+	assert.Nil(t, err)
+	return count
+}
+
+func TestPurge(t *testing.T) {
+	db := newTestDB(t, "users", "wallets", "addresses", "products", "user_like_product", "todos")
+	repositories := NewRepositories(db)
+	ctx := context.Background()
+
+	user := User{ID: "60", Name: Name{FirstName: "User 60"}, Addresses: []Address{{Address: "Jalan E"}}}
+	err := repositories.Users.Create(ctx, &user)
+	assert.Nil(t, err)
+
+	err = repositories.Todos.Delete(ctx, 1)
+	assert.Nil(t, err)
+	err = repositories.Products.Delete(ctx, "P002")
+	assert.Nil(t, err)
+	// User 22's wallet has an opening movement, so neither can be purged.
+	err = repositories.Users.Delete(ctx, "22")
+	assert.Nil(t, err)
+	err = repositories.Users.Delete(ctx, "60")
+	assert.Nil(t, err)
+
+	purger, err := NewPurger(db, time.Hour)
+	assert.Nil(t, err)
+	// Only todo 3, deleted long ago in the fixtures, is past the window.
+	reports, err := purger.Run(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []PurgeReport{
+		{Table: "todos", Purged: 1},
+		{Table: "products"},
+		{Table: "addresses"},
+		{Table: "wallets"},
+		{Table: "users"},
+	}, reports)
+	assert.Equal(t, int64(2), countAll(t, db, "todos"))
+
+	purger.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	expected := []PurgeReport{
+		{Table: "todos", Purged: 1},
+		{Table: "products", Purged: 1},
+		{Table: "addresses", Purged: 3},
+		{Table: "wallets", Blocked: 1},
+		{Table: "users", Purged: 1, Blocked: 1},
+	}
+
+	purger.DryRun = true
+	reports, err = purger.Run(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, reports)
+	assert.Equal(t, int64(2), countAll(t, db, "todos"))
+	assert.Equal(t, int64(4), countAll(t, db, "user_like_product"))
+
+	purger.DryRun = false
+	purger.BatchSize = 2
+	reports, err = purger.Run(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, reports)
+	assert.Equal(t, int64(1), countAll(t, db, "todos"))
+	assert.Equal(t, int64(2), countAll(t, db, "addresses"))
+	assert.Equal(t, int64(3), countAll(t, db, "user_like_product"))
+
+	_, err = repositories.Users.FindByID(ctx, "60")
+	assert.NotNil(t, err)
+	err = repositories.Users.Restore(ctx, "60")
+	assert.NotNil(t, err)
+	err = repositories.Users.Restore(ctx, "22")
+	assert.Nil(t, err)
+	_, err = repositories.Wallets.FindByID(ctx, "22")
+	assert.Nil(t, err)
+
+	_, err = NewPurger(db, time.Hour, &GuestBook{})
+	assert.Equal(t, ErrSoftDeleteUnsupported, err)
+}
@@ -0,0 +1,133 @@
+package golang_gorm
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// WalletMismatch is a wallet whose stored balance differs from the sum of its
+// movements in wallet_transactions.
+type WalletMismatch struct {
+	WalletId  string
+	UserId    string
+	Currency  string
+	Balance   int64
+	Ledger    int64
+	Movements int64
+	UpdatedAt time.Time
+}
+
+// Difference is how much the stored balance exceeds the ledger.
+func (m WalletMismatch) Difference() int64 {
+	return m.Balance - m.Ledger
+}
+
+func (m WalletMismatch) String() string {
+	return fmt.Sprintf("wallet %s of user %s: balance %s, ledger %s over %d movements, difference %s",
+		m.WalletId, m.UserId,
+		Money{Amount: m.Balance, Currency: m.Currency},
+		Money{Amount: m.Ledger, Currency: m.Currency},
+		m.Movements,
+		Money{Amount: m.Difference(), Currency: m.Currency})
+}
+
+// Reconciler checks Wallet.Balance, which db.Save or a raw update can
+// overwrite, against the immutable movement history.
+type Reconciler struct {
+	db *gorm.DB
+}
+
+func NewReconciler(db *gorm.DB) *Reconciler {
+	return &Reconciler{db: db}
+}
+
+// Check returns every wallet whose balance does not match its ledger, or only
+// the given wallets when IDs are passed.
+func (r *Reconciler) Check(ctx context.Context, walletIDs ...string) ([]WalletMismatch, error) {
+	db := FromContext(ctx, r.db)
+
+	ledger := db.Model(&WalletTransaction{}).
+		Select("wallet_id, " + signedAmountSQL + " AS ledger, COUNT(*) AS movements").
+		Group("wallet_id")
+	query := db.Model(&Wallet{}).
+		Select("wallets.id AS wallet_id, wallets.user_id, wallets.currency, wallets.balance, wallets.updated_at, "+
+			"COALESCE(ledger.ledger, 0) AS ledger, COALESCE(ledger.movements, 0) AS movements").
+		Joins("LEFT JOIN (?) ledger ON ledger.wallet_id = wallets.id", ledger).
+		Where("wallets.balance <> COALESCE(ledger.ledger, 0)")
+	if len(walletIDs) > 0 {
+		query = query.Where("wallets.id IN ?", walletIDs)
+	}
+
+	var mismatches []WalletMismatch
+	err := query.Order("wallets.id asc").Scan(&mismatches).Error
+	return mismatches, err
+}
+
+// Repair sets the balance of the wallet back to its ledger. The wallet row is
+// locked while the ledger is summed, so a concurrent transfer cannot slip in
+// between. It returns the mismatch it fixed, or nil when the wallet was
+// already consistent.
+func (r *Reconciler) Repair(ctx context.Context, walletID string) (*WalletMismatch, error) {
+	var repaired *WalletMismatch
+	err := FromContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var wallet Wallet
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&wallet, "id = ?", walletID).Error
+		if err != nil {
+			return err
+		}
+
+		var ledger struct {
+			Ledger    int64
+			Movements int64
+		}
+		err = tx.Model(&WalletTransaction{}).Select(signedAmountSQL+" AS ledger, COUNT(*) AS movements").
+			Where("wallet_id = ?", walletID).Scan(&ledger).Error
+		if err != nil {
+			return err
+		}
+
+		mismatch := WalletMismatch{
+			WalletId:  wallet.ID,
+			UserId:    wallet.UserId,
+			Currency:  wallet.Currency,
+			Balance:   wallet.Balance,
+			Ledger:    ledger.Ledger,
+			Movements: ledger.Movements,
+			UpdatedAt: wallet.UpdatedAt,
+		}
+		if mismatch.Difference() == 0 {
+			return nil
+		}
+
+		err = tx.Model(&wallet).Update("balance", mismatch.Ledger).Error
+		if err != nil {
+			return err
+		}
+		repaired = &mismatch
+		return nil
+	})
+	return repaired, err
+}
+
+// RepairAll repairs every wallet Check reports and returns what it fixed.
+func (r *Reconciler) RepairAll(ctx context.Context, walletIDs ...string) ([]WalletMismatch, error) {
+	mismatches, err := r.Check(ctx, walletIDs...)
+	if err != nil {
+		return nil, err
+	}
+
+	var repaired []WalletMismatch
+	for _, mismatch := range mismatches {
+		fixed, err := r.Repair(ctx, mismatch.WalletId)
+		if err != nil {
+			return repaired, fmt.Errorf("wallet %s: %w", mismatch.WalletId, err)
+		}
+		if fixed != nil {
+			repaired = append(repaired, *fixed)
+		}
+	}
+	return repaired, nil
+}
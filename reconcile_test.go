@@ -0,0 +1,61 @@
+package golang_gorm
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReconcileWallets(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+	reconciler := NewReconciler(db)
+	ctx := context.Background()
+
+	mismatches, err := reconciler.Check(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, mismatches)
+
+	_, err = NewTransferService(db).Transfer(ctx, "1", "01", 250000)
+	assert.Nil(t, err)
+
+	// Overwrite balances behind the ledger's back.
+	wallet := Wallet{}
+	err = db.Take(&wallet, "id = ?", "01").Error
+	assert.Nil(t, err)
+	wallet.Balance = 5
+	err = db.Save(&wallet).Error
+	assert.Nil(t, err)
+	err = db.Model(&Wallet{}).Where("id = ?", "20").Update("balance", 0).Error
+	assert.Nil(t, err)
+
+	mismatches, err = reconciler.Check(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(mismatches))
+	assert.Equal(t, "01", mismatches[0].WalletId)
+	assert.Equal(t, "2", mismatches[0].UserId)
+	assert.Equal(t, int64(5), mismatches[0].Balance)
+	assert.Equal(t, int64(1250000), mismatches[0].Ledger)
+	assert.Equal(t, int64(2), mismatches[0].Movements)
+	assert.Equal(t, int64(-1249995), mismatches[0].Difference())
+	assert.False(t, mismatches[0].UpdatedAt.IsZero())
+	assert.Equal(t, "wallet 01 of user 2: balance IDR 0.05, ledger IDR 12500.00 over 2 movements, difference IDR -12499.95", mismatches[0].String())
+	assert.Equal(t, "20", mismatches[1].WalletId)
+
+	mismatches, err = reconciler.Check(ctx, "20")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(mismatches))
+
+	repaired, err := reconciler.RepairAll(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(repaired))
+	assert.Equal(t, int64(1250000), walletBalance(t, db, "01"))
+	assert.Equal(t, int64(1000000000), walletBalance(t, db, "20"))
+
+	fixed, err := reconciler.Repair(ctx, "01")
+	assert.Nil(t, err)
+	assert.Nil(t, fixed)
+
+	mismatches, err = reconciler.Check(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, mismatches)
+}
@@ -0,0 +1,51 @@
+package golang_gorm
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+// Repositories holds one repository per model, all on the same connection or
+// transaction.
+type Repositories struct {
+	Users      UserRepository
+	Wallets    WalletRepository
+	Addresses  AddressRepository
+	Products   ProductRepository
+	Todos      TodoRepository
+	GuestBooks GuestBookRepository
+	UserLogs   UserLogRepository
+}
+
+func NewRepositories(db *gorm.DB) Repositories {
+	return Repositories{
+		Users:      NewUserRepository(db),
+		Wallets:    NewWalletRepository(db),
+		Addresses:  NewAddressRepository(db),
+		Products:   NewProductRepository(db),
+		Todos:      NewTodoRepository(db),
+		GuestBooks: NewGuestBookRepository(db),
+		UserLogs:   NewUserLogRepository(db),
+	}
+}
+
+// UnitOfWork runs fn with repositories that share one transaction. The
+// transaction commits when fn returns nil and rolls back otherwise; inside
+// TxManager.WithinTx it becomes a savepoint of the surrounding transaction.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(repositories Repositories) error) error
+}
+
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) UnitOfWork {
+	return &unitOfWork{db: db}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(repositories Repositories) error) error {
+	return NewTxManager(u.db).WithinTx(ctx, func(ctx context.Context) error {
+		return fn(NewRepositories(FromContext(ctx, u.db)))
+	})
+}
@@ -0,0 +1,146 @@
+package golang_gorm
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestUserRepository(t *testing.T) {
+	db := newTestDB(t, "users", "wallets", "addresses", "products", "user_like_product")
+	users := NewUserRepository(db)
+	ctx := context.Background()
+
+	user, err := users.FindByID(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, "Brian", user.Name.FirstName)
+
+	_, err = users.FindByID(ctx, "missing")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	found, err := users.Search(ctx, "Anashari")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(found))
+
+	err = users.LikeProduct(ctx, "1", "P002")
+	assert.Nil(t, err)
+	err = users.LikeProduct(ctx, "1", "P002")
+	assert.Nil(t, err)
+	err = users.LikeProduct(ctx, "1", "missing")
+	assert.True(t, errors.Is(err, ErrFKViolation))
+
+	user, err = users.FindWithRelations(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(user.Wallets))
+	assert.Equal(t, "1", user.Wallets[0].ID)
+	assert.Equal(t, 2, len(user.LikeProducts))
+
+	user = User{ID: "50", Password: "rahasia", Name: Name{FirstName: "User 50"}}
+	err = users.Create(ctx, &user)
+	assert.Nil(t, err)
+	user.Name.LastName = "Repository"
+	err = users.Update(ctx, &user)
+	assert.Nil(t, err)
+	found, err = users.Search(ctx, "Repository")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(found))
+
+	// Wildcards in the name match only themselves.
+	found, err = users.Search(ctx, "_")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(found))
+	user.Name.MiddleName = `50%_\`
+	err = users.Update(ctx, &user)
+	assert.Nil(t, err)
+	for _, name := range []string{"%", "0%_", `\`} {
+		found, err = users.Search(ctx, name)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"50"}, userIDs(found))
+	}
+	found, err = users.Search(ctx, "5_%")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(found))
+
+	// Update never creates the user it cannot find.
+	missing := User{ID: "51", Password: "rahasia", Name: Name{FirstName: "User 51"}}
+	err = users.Update(ctx, &missing)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	_, err = users.FindByID(ctx, "51")
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	err = users.Delete(ctx, "50")
+	assert.Nil(t, err)
+	err = users.Delete(ctx, "50")
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	err = users.Update(ctx, &user)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+}
+
+func TestRepositories(t *testing.T) {
+	db := newTestDB(t, "users", "wallets", "addresses", "products", "user_like_product", "todos")
+	repositories := NewRepositories(db)
+	ctx := context.Background()
+
+	wallets, err := repositories.Wallets.FindByBalance(ctx, 0, 1000000)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(wallets))
+	assert.Equal(t, "01", wallets[0].ID)
+
+	addresses, err := repositories.Addresses.FindByUserID(ctx, "22")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, addresses)
+
+	products, err := repositories.Products.LikedBy(ctx, "2")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(products))
+
+	todos, err := repositories.Todos.FindByUserID(ctx, "1")
+	assert.Nil(t, err)
+	count := len(todos)
+	err = repositories.Todos.Delete(ctx, todos[0].ID)
+	assert.Nil(t, err)
+	todos, err = repositories.Todos.FindByUserID(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, count-1, len(todos))
+
+	err = repositories.GuestBooks.Create(ctx, &GuestBook{Name: "Guest", Email: "guest@example.com", Message: "Hello"})
+	assert.Nil(t, err)
+	latest, err := repositories.GuestBooks.Latest(ctx, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(latest))
+}
+
+func TestUnitOfWork(t *testing.T) {
+	db := newTestDB(t, "users")
+	work := NewUnitOfWork(db)
+	ctx := context.Background()
+
+	err := work.Do(ctx, func(repositories Repositories) error {
+		user := User{ID: "50", Password: "rahasia", Name: Name{FirstName: "User 50"}}
+		if err := repositories.Users.Create(ctx, &user); err != nil {
+			return err
+		}
+		return repositories.Addresses.Create(ctx, &Address{UserId: user.ID, Address: "Jalan Belum Ada"})
+	})
+	assert.Nil(t, err)
+
+	failure := errors.New("rollback")
+	err = work.Do(ctx, func(repositories Repositories) error {
+		user := User{ID: "51", Password: "rahasia", Name: Name{FirstName: "User 51"}}
+		if err := repositories.Users.Create(ctx, &user); err != nil {
+			return err
+		}
+		return failure
+	})
+	assert.Equal(t, failure, err)
+
+	repositories := NewRepositories(db)
+	addresses, err := repositories.Addresses.FindByUserID(ctx, "50")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(addresses))
+	_, err = repositories.Users.FindByID(ctx, "51")
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+}
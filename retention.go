@@ -0,0 +1,392 @@
+package golang_gorm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultRetentionBatchSize = 1000
+	userLogArchivePattern     = "user_logs-%d-%d-%d-%d"
+)
+
+// ArchivedBatch identifies one batch held by an ArchiveStore.
+type ArchivedBatch struct {
+	Key         string
+	FirstId     int
+	LastId      int
+	CreatedFrom int64
+	CreatedTo   int64
+}
+
+// ArchiveStore keeps the user_logs that UserLogRetention moved out of the hot
+// table. A transactional store writes and removes batches in the transaction
+// of ctx, so entries move atomically; any other store only has batches removed
+// after the entries are safely back in user_logs.
+type ArchiveStore interface {
+	// Transactional reports whether Write and Remove take part in the
+	// database transaction of ctx.
+	Transactional() bool
+	// Write keeps one non-empty batch of entries ordered by ID.
+	Write(ctx context.Context, logs []UserLog) error
+	// Batches lists the batches holding entries created in [from, to); zero
+	// leaves a bound open.
+	Batches(ctx context.Context, from, to int64) ([]ArchivedBatch, error)
+	Read(ctx context.Context, batch ArchivedBatch) ([]UserLog, error)
+	Remove(ctx context.Context, batch ArchivedBatch) error
+}
+
+// TableArchive stores batches as compressed rows of user_log_archives.
+type TableArchive struct {
+	db *gorm.DB
+}
+
+func NewTableArchive(db *gorm.DB) *TableArchive {
+	return &TableArchive{db: db}
+}
+
+func (a *TableArchive) Transactional() bool {
+	return true
+}
+
+func (a *TableArchive) Write(ctx context.Context, logs []UserLog) error {
+	var data bytes.Buffer
+	if err := encodeUserLogs(&data, logs); err != nil {
+		return err
+	}
+	from, to := userLogSpan(logs)
+	archive := UserLogArchive{
+		FirstId:     logs[0].ID,
+		LastId:      logs[len(logs)-1].ID,
+		CreatedFrom: from,
+		CreatedTo:   to,
+		Entries:     len(logs),
+		Data:        data.Bytes(),
+	}
+	return Classify(FromContext(ctx, a.db).Create(&archive).Error)
+}
+
+func (a *TableArchive) Batches(ctx context.Context, from, to int64) ([]ArchivedBatch, error) {
+	query := FromContext(ctx, a.db).Model(&UserLogArchive{}).
+		Select("id, first_id, last_id, created_from, created_to")
+	if from != 0 {
+		query = query.Where("created_to >= ?", from)
+	}
+	if to != 0 {
+		query = query.Where("created_from < ?", to)
+	}
+
+	var archives []UserLogArchive
+	if err := query.Order("id asc").Find(&archives).Error; err != nil {
+		return nil, Classify(err)
+	}
+	batches := make([]ArchivedBatch, len(archives))
+	for i, archive := range archives {
+		batches[i] = ArchivedBatch{
+			Key:         fmt.Sprint(archive.ID),
+			FirstId:     archive.FirstId,
+			LastId:      archive.LastId,
+			CreatedFrom: archive.CreatedFrom,
+			CreatedTo:   archive.CreatedTo,
+		}
+	}
+	return batches, nil
+}
+
+func (a *TableArchive) Read(ctx context.Context, batch ArchivedBatch) ([]UserLog, error) {
+	var archive UserLogArchive
+	if err := FromContext(ctx, a.db).Take(&archive, "id = ?", batch.Key).Error; err != nil {
+		return nil, Classify(err)
+	}
+	return decodeUserLogs(bytes.NewReader(archive.Data))
+}
+
+func (a *TableArchive) Remove(ctx context.Context, batch ArchivedBatch) error {
+	return Classify(FromContext(ctx, a.db).Delete(&UserLogArchive{}, "id = ?", batch.Key).Error)
+}
+
+// FileArchive stores each batch as a gzipped NDJSON file in Dir, named after
+// the CreatedAt and ID range it holds plus a fresh ID, so a batch rewritten with
+// the same range never takes the place of the one it replaces. Files are not transactional: when the
+// delete that follows a Write fails, the next run writes the same entries
+// again and Restore skips the ones already present.
+type FileArchive struct {
+	Dir string
+}
+
+func NewFileArchive(dir string) *FileArchive {
+	return &FileArchive{Dir: dir}
+}
+
+func (a *FileArchive) Transactional() bool {
+	return false
+}
+
+func (a *FileArchive) Write(ctx context.Context, logs []UserLog) error {
+	from, to := userLogSpan(logs)
+	name := fmt.Sprintf(userLogArchivePattern+"-%s.ndjson.gz", from, to, logs[0].ID, logs[len(logs)-1].ID, DefaultIDGenerator.NewID())
+
+	// Write under a temporary name so a crash never leaves a truncated batch.
+	file, err := os.CreateTemp(a.Dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if err := encodeUserLogs(file, logs); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), filepath.Join(a.Dir, name))
+}
+
+func (a *FileArchive) Batches(ctx context.Context, from, to int64) ([]ArchivedBatch, error) {
+	entries, err := os.ReadDir(a.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var batches []ArchivedBatch
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".ndjson.gz") {
+			continue
+		}
+		// Only the range is read; the ID after it, and its absence in
+		// older names, do not matter.
+		batch := ArchivedBatch{Key: entry.Name()}
+		_, err := fmt.Sscanf(entry.Name(), userLogArchivePattern, &batch.CreatedFrom, &batch.CreatedTo, &batch.FirstId, &batch.LastId)
+		if err != nil {
+			continue
+		}
+		if (from != 0 && batch.CreatedTo < from) || (to != 0 && batch.CreatedFrom >= to) {
+			continue
+		}
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
+
+func (a *FileArchive) Read(ctx context.Context, batch ArchivedBatch) ([]UserLog, error) {
+	file, err := os.Open(filepath.Join(a.Dir, batch.Key))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return decodeUserLogs(file)
+}
+
+func (a *FileArchive) Remove(ctx context.Context, batch ArchivedBatch) error {
+	return os.Remove(filepath.Join(a.Dir, batch.Key))
+}
+
+// UserLogRetention moves user_logs older than MaxAge into an ArchiveStore.
+// Each batch is archived and deleted by primary key in its own short
+// transaction, so writers of new entries are never blocked for long.
+type UserLogRetention struct {
+	MaxAge    time.Duration
+	BatchSize int
+	// Pause is slept between batches to leave room for other work.
+	Pause time.Duration
+
+	db    *gorm.DB
+	tx    *TxManager
+	store ArchiveStore
+	now   func() time.Time
+}
+
+func NewUserLogRetention(db *gorm.DB, store ArchiveStore, maxAge time.Duration) *UserLogRetention {
+	return &UserLogRetention{
+		MaxAge:    maxAge,
+		BatchSize: DefaultRetentionBatchSize,
+		db:        db,
+		tx:        NewTxManager(db),
+		store:     store,
+		now:       time.Now,
+	}
+}
+
+// Run archives every entry created before now minus MaxAge and returns how
+// many it moved.
+func (r *UserLogRetention) Run(ctx context.Context) (int, error) {
+	if r.MaxAge <= 0 {
+		return 0, errors.New("retention needs a positive MaxAge")
+	}
+	size := max(r.BatchSize, 1)
+	cutoff := r.now().Add(-r.MaxAge).UnixMilli()
+
+	archived := 0
+	for {
+		var logs []UserLog
+		err := FromContext(ctx, r.db).Where("created_at < ?", cutoff).Order("id asc").Limit(size).Find(&logs).Error
+		if err != nil {
+			return archived, Classify(err)
+		}
+		if len(logs) == 0 {
+			return archived, nil
+		}
+
+		err = r.tx.WithinTx(ctx, func(ctx context.Context) error {
+			if err := r.store.Write(ctx, logs); err != nil {
+				return err
+			}
+			ids := make([]int, len(logs))
+			for i, log := range logs {
+				ids[i] = log.ID
+			}
+			return Classify(FromContext(ctx, r.db).Delete(&UserLog{}, "id IN ?", ids).Error)
+		})
+		if err != nil {
+			return archived, err
+		}
+		archived += len(logs)
+
+		if len(logs) < size {
+			return archived, nil
+		}
+		if r.Pause > 0 {
+			if err := wait(ctx, r.Pause); err != nil {
+				return archived, err
+			}
+		}
+	}
+}
+
+// Restore moves the archived entries created in [from, to) back into
+// user_logs with their original IDs and returns how many it restored. The
+// other entries of a batch are written back to the store as a new batch.
+func (r *UserLogRetention) Restore(ctx context.Context, from, to int64) (int, error) {
+	batches, err := r.store.Batches(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	restored := 0
+	for _, batch := range batches {
+		logs, err := r.store.Read(ctx, batch)
+		if err != nil {
+			return restored, fmt.Errorf("reading archive %s: %w", batch.Key, err)
+		}
+		var inRange, rest []UserLog
+		for _, log := range logs {
+			if (from == 0 || log.CreatedAt >= from) && (to == 0 || log.CreatedAt < to) {
+				inRange = append(inRange, log)
+			} else {
+				rest = append(rest, log)
+			}
+		}
+		if len(inRange) == 0 {
+			continue
+		}
+
+		var inserted int64
+		err = r.tx.WithinTx(ctx, func(ctx context.Context) error {
+			result := FromContext(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).
+				CreateInBatches(&inRange, max(r.BatchSize, 1))
+			if result.Error != nil {
+				return Classify(result.Error)
+			}
+			inserted = result.RowsAffected
+			if !r.store.Transactional() {
+				return nil
+			}
+			return r.replace(ctx, batch, rest)
+		})
+		if err != nil {
+			return restored, err
+		}
+		// The entries are committed; only now can a store that cannot roll
+		// back let go of them. Should this fail, the entries stay archived
+		// too and a later Restore skips them.
+		if !r.store.Transactional() {
+			if err := r.replace(ctx, batch, rest); err != nil {
+				return restored + int(inserted), err
+			}
+		}
+		restored += int(inserted)
+	}
+	return restored, nil
+}
+
+// replace swaps batch for a new one holding rest, writing it first so no
+// entry is ever missing from the store.
+func (r *UserLogRetention) replace(ctx context.Context, batch ArchivedBatch, rest []UserLog) error {
+	if len(rest) > 0 {
+		if err := r.store.Write(ctx, rest); err != nil {
+			return err
+		}
+	}
+	return r.store.Remove(ctx, batch)
+}
+
+// archivedUserLog is the archive record of a UserLog. It keeps UpdatedAt,
+// which UserLog leaves out of its JSON.
+type archivedUserLog struct {
+	UserLog
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+func encodeUserLogs(w io.Writer, logs []UserLog) error {
+	writer := gzip.NewWriter(w)
+	encoder := json.NewEncoder(writer)
+	for _, log := range logs {
+		if err := encoder.Encode(archivedUserLog{UserLog: log, UpdatedAt: log.UpdatedAt}); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+func decodeUserLogs(r io.Reader) ([]UserLog, error) {
+	reader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var logs []UserLog
+	decoder := json.NewDecoder(reader)
+	for {
+		var record archivedUserLog
+		err := decoder.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			return logs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		log := record.UserLog
+		log.UpdatedAt = record.UpdatedAt
+		if log.UpdatedAt == 0 {
+			// Archived before updated_at was kept.
+			log.UpdatedAt = log.CreatedAt
+		}
+		logs = append(logs, log)
+	}
+}
+
+// userLogSpan returns the oldest and newest CreatedAt of logs.
+func userLogSpan(logs []UserLog) (int64, int64) {
+	from, to := logs[0].CreatedAt, logs[0].CreatedAt
+	for _, log := range logs[1:] {
+		from = min(from, log.CreatedAt)
+		to = max(to, log.CreatedAt)
+	}
+	return from, to
+}
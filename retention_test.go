@@ -0,0 +1,154 @@
+package golang_gorm
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func seedOldUserLogs(t *testing.T, db *gorm.DB, n int) {
+	logs := make([]UserLog, n)
+	for i := range logs {
+		logs[i] = UserLog{UserId: "1", Action: UserLogUpdate, Payload: JSON(`{"n":1}`), CreatedAt: int64(1000 * (i + 1)), UpdatedAt: int64(1000*(i+1) + 500)}
+	}
+	err := db.Create(&logs).Error
+	assert.Nil(t, err)
+}
+
+func countUserLogs(t *testing.T, db *gorm.DB) int64 {
+	var count int64
+	err := db.Model(&UserLog{}).Count(&count).Error
+	assert.Nil(t, err)
+	return count
+}
+
+func testRetention(t *testing.T, db *gorm.DB, store ArchiveStore) {
+	seedOldUserLogs(t, db, 7)
+	err := db.Create(&UserLog{UserId: "1", Action: UserLogCreate}).Error
+	assert.Nil(t, err)
+
+	retention := NewUserLogRetention(db, store, 24*time.Hour)
+	retention.BatchSize = 3
+	ctx := context.Background()
+
+	archived, err := retention.Run(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 7, archived)
+	assert.Equal(t, int64(1), countUserLogs(t, db))
+
+	batches, err := store.Batches(ctx, 0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(batches))
+
+	archived, err = retention.Run(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, archived)
+
+	// 2000 and 3000 lie in the first batch, 4000 in the second.
+	restored, err := retention.Restore(ctx, 2000, 4001)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, restored)
+	assert.Equal(t, int64(4), countUserLogs(t, db))
+
+	var log UserLog
+	err = db.Take(&log, "created_at = ?", 3000).Error
+	assert.Nil(t, err)
+	assert.Equal(t, 3, log.ID)
+	assert.Equal(t, int64(3500), log.UpdatedAt)
+	assert.Equal(t, UserLogUpdate, log.Action)
+	assert.JSONEq(t, `{"n":1}`, string(log.Payload))
+	err = db.Take(&UserLog{}, "created_at = ?", 5000).Error
+	assert.NotNil(t, err)
+
+	// The rest of the two batches is archived again; the third is untouched.
+	batches, err = store.Batches(ctx, 0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(batches))
+	spans := map[[2]int]bool{}
+	for _, batch := range batches {
+		spans[[2]int{batch.FirstId, batch.LastId}] = true
+	}
+	assert.Equal(t, map[[2]int]bool{{1, 1}: true, {5, 6}: true, {7, 7}: true}, spans)
+
+	restored, err = retention.Restore(ctx, 0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, restored)
+	assert.Equal(t, int64(8), countUserLogs(t, db))
+	batches, err = store.Batches(ctx, 0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(batches))
+}
+
+func TestRetentionTableArchive(t *testing.T) {
+	db := newTestDB(t)
+	testRetention(t, db, NewTableArchive(db))
+}
+
+func TestRetentionFileArchive(t *testing.T) {
+	db := newTestDB(t)
+	testRetention(t, db, NewFileArchive(t.TempDir()))
+}
+
+func TestRetentionTableArchiveRollback(t *testing.T) {
+	db := newTestDB(t)
+	seedOldUserLogs(t, db, 3)
+	// Without its table the archive fails, and nothing may be deleted.
+	err := db.Migrator().DropTable(&UserLogArchive{})
+	assert.Nil(t, err)
+
+	_, err = NewUserLogRetention(db, NewTableArchive(db), time.Hour).Run(context.Background())
+	assert.NotNil(t, err)
+	assert.Equal(t, int64(3), countUserLogs(t, db))
+}
+
+func TestRetentionFileArchiveKeptOnFailedRestore(t *testing.T) {
+	db := newTestDB(t)
+	seedOldUserLogs(t, db, 3)
+	store := NewFileArchive(t.TempDir())
+	retention := NewUserLogRetention(db, store, time.Hour)
+	ctx := context.Background()
+
+	_, err := retention.Run(ctx)
+	assert.Nil(t, err)
+	// Without user_logs nothing can be restored, and the file must survive.
+	err = db.Migrator().DropTable(&UserLog{})
+	assert.Nil(t, err)
+
+	_, err = retention.Restore(ctx, 0, 0)
+	assert.NotNil(t, err)
+	batches, err := store.Batches(ctx, 0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(batches))
+	logs, err := store.Read(ctx, batches[0])
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(logs))
+}
+
+func TestRetentionFileArchiveInteriorRestore(t *testing.T) {
+	db := newTestDB(t)
+	seedOldUserLogs(t, db, 5)
+	store := NewFileArchive(t.TempDir())
+	retention := NewUserLogRetention(db, store, time.Hour)
+	ctx := context.Background()
+
+	_, err := retention.Run(ctx)
+	assert.Nil(t, err)
+
+	// The rest of the batch keeps its span and end IDs, 1 to 5.
+	restored, err := retention.Restore(ctx, 3000, 3001)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, restored)
+	batches, err := store.Batches(ctx, 0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(batches))
+	logs, err := store.Read(ctx, batches[0])
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(logs))
+
+	restored, err = retention.Restore(ctx, 0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, restored)
+	assert.Equal(t, int64(5), countUserLogs(t, db))
+}
@@ -0,0 +1,144 @@
+package golang_gorm
+
+import (
+	"context"
+	"errors"
+	gomysql "github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	mysqlLockWaitTimeout = 1205
+	mysqlDeadlock        = 1213
+)
+
+// RetryPolicy says how often and how patiently RetryTransaction retries.
+type RetryPolicy struct {
+	// MaxAttempts counts the first attempt; 1 disables retries.
+	MaxAttempts int
+	// Delays grow from BaseDelay, doubling per attempt up to MaxDelay, and
+	// each sleep is a random duration below the current delay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	Metrics   RetryMetrics
+}
+
+// RetryMetrics observes RetryTransaction.
+type RetryMetrics interface {
+	// Retry is called before sleeping delay ahead of attempt number attempt.
+	Retry(attempt int, err error, delay time.Duration)
+	// Done is called once per transaction with the attempts made and the
+	// final error, nil on success.
+	Done(attempts int, err error)
+}
+
+// RetryCounters is a RetryMetrics that counts, safe for concurrent use.
+type RetryCounters struct {
+	Transactions     atomic.Int64
+	Retries          atomic.Int64
+	Deadlocks        atomic.Int64
+	LockWaitTimeouts atomic.Int64
+	// Exhausted counts transactions that still failed with a retryable error
+	// after the last attempt.
+	Exhausted atomic.Int64
+}
+
+func (c *RetryCounters) Retry(attempt int, err error, delay time.Duration) {
+	c.Retries.Add(1)
+	switch mysqlErrorNumber(err) {
+	case mysqlDeadlock:
+		c.Deadlocks.Add(1)
+	case mysqlLockWaitTimeout:
+		c.LockWaitTimeouts.Add(1)
+	}
+}
+
+func (c *RetryCounters) Done(attempts int, err error) {
+	c.Transactions.Add(1)
+	if IsRetryable(err) {
+		c.Exhausted.Add(1)
+	}
+}
+
+// TransactionRetries collects the metrics of DefaultRetryPolicy.
+var TransactionRetries = &RetryCounters{}
+
+// DefaultRetryPolicy is used by TransferService and WalletService.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   20 * time.Millisecond,
+	MaxDelay:    time.Second,
+	Metrics:     TransactionRetries,
+}
+
+// IsRetryable reports whether err is a MySQL deadlock (1213) or lock wait
+// timeout (1205). MySQL has rolled back the transaction, or in the case of a
+// timeout at least the statement, so running it again from the start is safe.
+func IsRetryable(err error) bool {
+	number := mysqlErrorNumber(err)
+	return number == mysqlDeadlock || number == mysqlLockWaitTimeout
+}
+
+func mysqlErrorNumber(err error) uint16 {
+	var mysqlErr *gomysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number
+	}
+	return 0
+}
+
+// RetryTransaction runs fn in db.Transaction and runs it again, after a
+// jittered backoff, while it fails with a retryable error. fn must not have
+// effects outside the database, or they repeat with it.
+//
+// When ctx already carries a transaction from TxManager, fn runs once in a
+// savepoint: a deadlock aborts the outer transaction too, so only its owner
+// can retry.
+func RetryTransaction(ctx context.Context, db *gorm.DB, policy RetryPolicy, fn func(tx *gorm.DB) error) error {
+	if InTx(ctx) {
+		return FromContext(ctx, db).Transaction(fn)
+	}
+
+	attempts := max(policy.MaxAttempts, 1)
+	delay := policy.BaseDelay
+	var err error
+	attempt := 1
+	for ; ; attempt++ {
+		err = FromContext(ctx, db).Transaction(fn)
+		if err == nil || !IsRetryable(err) || attempt == attempts {
+			break
+		}
+
+		sleep := time.Duration(0)
+		if delay > 0 {
+			sleep = rand.N(delay)
+		}
+		if policy.Metrics != nil {
+			policy.Metrics.Retry(attempt+1, err, sleep)
+		}
+		if waitErr := wait(ctx, sleep); waitErr != nil {
+			err = errors.Join(err, waitErr)
+			break
+		}
+		delay = min(delay*2, max(policy.MaxDelay, policy.BaseDelay))
+	}
+
+	if policy.Metrics != nil {
+		policy.Metrics.Done(attempt, err)
+	}
+	return err
+}
+
+func wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,113 @@
+package golang_gorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	gomysql "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+var (
+	deadlock        = &gomysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock; try restarting transaction"}
+	lockWaitTimeout = &gomysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded; try restarting transaction"}
+)
+
+func testRetryPolicy(metrics RetryMetrics) RetryPolicy {
+	return RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 4 * time.Millisecond, Metrics: metrics}
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, IsRetryable(deadlock))
+	assert.True(t, IsRetryable(fmt.Errorf("transfer: %w", lockWaitTimeout)))
+	assert.False(t, IsRetryable(&gomysql.MySQLError{Number: 1062}))
+	assert.False(t, IsRetryable(gorm.ErrRecordNotFound))
+	assert.False(t, IsRetryable(nil))
+}
+
+func TestRetryTransaction(t *testing.T) {
+	db := newTestDB(t)
+	metrics := &RetryCounters{}
+	ctx := context.Background()
+
+	attempts := 0
+	err := RetryTransaction(ctx, db, testRetryPolicy(metrics), func(tx *gorm.DB) error {
+		attempts++
+		err := tx.Create(&User{ID: "50", Password: "rahasia", Name: Name{FirstName: "User 50"}}).Error
+		if err != nil {
+			return err
+		}
+		switch attempts {
+		case 1:
+			return deadlock
+		case 2:
+			return lockWaitTimeout
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, int64(1), metrics.Transactions.Load())
+	assert.Equal(t, int64(2), metrics.Retries.Load())
+	assert.Equal(t, int64(1), metrics.Deadlocks.Load())
+	assert.Equal(t, int64(1), metrics.LockWaitTimeouts.Load())
+	assert.Equal(t, int64(0), metrics.Exhausted.Load())
+
+	var count int64
+	err = db.Model(&User{}).Where("id = ?", "50").Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestRetryTransactionGivesUp(t *testing.T) {
+	db := newTestDB(t)
+	metrics := &RetryCounters{}
+	ctx := context.Background()
+
+	attempts := 0
+	err := RetryTransaction(ctx, db, testRetryPolicy(metrics), func(tx *gorm.DB) error {
+		attempts++
+		return deadlock
+	})
+	assert.Equal(t, deadlock, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, int64(1), metrics.Exhausted.Load())
+
+	attempts = 0
+	failure := errors.New("not retryable")
+	err = RetryTransaction(ctx, db, testRetryPolicy(metrics), func(tx *gorm.DB) error {
+		attempts++
+		return failure
+	})
+	assert.Equal(t, failure, err)
+	assert.Equal(t, 1, attempts)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	attempts = 0
+	err = RetryTransaction(cancelled, db, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour}, func(tx *gorm.DB) error {
+		attempts++
+		cancel()
+		return deadlock
+	})
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRetryTransactionInsideTx(t *testing.T) {
+	db := newTestDB(t)
+	metrics := &RetryCounters{}
+
+	attempts := 0
+	err := NewTxManager(db).WithinTx(context.Background(), func(ctx context.Context) error {
+		return RetryTransaction(ctx, db, testRetryPolicy(metrics), func(tx *gorm.DB) error {
+			attempts++
+			return deadlock
+		})
+	})
+	assert.Equal(t, deadlock, err)
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, int64(0), metrics.Retries.Load())
+}
@@ -0,0 +1,196 @@
+package golang_gorm
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"reflect"
+)
+
+const softDeleteIDsKey = "soft_delete:ids"
+
+// WithDeleted is a Scope that also returns soft deleted rows.
+func WithDeleted() Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}
+}
+
+// OnlyDeleted is a Scope that returns soft deleted rows only.
+func OnlyDeleted() Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped().Where(clause.Expr{
+			SQL:  "? IS NOT NULL",
+			Vars: []interface{}{clause.Column{Table: clause.CurrentTable, Name: "deleted_at"}},
+		})
+	}
+}
+
+// SoftDeletePlugin soft deletes the children of a soft deleted row along with
+// it: every has-one and has-many association whose model has a gorm.DeletedAt
+// field, such as the wallet and addresses of a User. Deleting with Unscoped
+// removes the row for good and leaves its children to the foreign keys.
+// RestoreDeleted undoes the cascade.
+type SoftDeletePlugin struct{}
+
+func NewSoftDeletePlugin() *SoftDeletePlugin {
+	return &SoftDeletePlugin{}
+}
+
+func (p *SoftDeletePlugin) Name() string {
+	return "soft_delete"
+}
+
+func (p *SoftDeletePlugin) Initialize(db *gorm.DB) error {
+	err := db.Callback().Delete().After("gorm:before_delete").Before("gorm:delete").
+		Register("soft_delete:before_delete", p.beforeDelete)
+	if err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Before("gorm:commit_or_rollback_transaction").
+		Register("soft_delete:after_delete", p.afterDelete)
+}
+
+// beforeDelete keeps the primary keys of the rows about to be deleted, which
+// can no longer be told apart once deleted_at is set.
+func (p *SoftDeletePlugin) beforeDelete(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || db.DryRun || stmt.Unscoped || stmt.Schema == nil ||
+		softDeleteField(stmt.Schema) == nil || len(cascades(stmt.Schema)) == 0 {
+		return
+	}
+	primary := stmt.Schema.PrioritizedPrimaryField
+
+	var ids []interface{}
+	for _, row := range rows(stmt.ReflectValue) {
+		if id, zero := primary.ValueOf(stmt.Context, row); !zero {
+			ids = append(ids, id)
+		}
+	}
+	where, hasWhere := stmt.Clauses["WHERE"]
+	if len(ids) == 0 && !hasWhere {
+		return
+	}
+
+	tx := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Model(reflect.New(stmt.Schema.ModelType).Interface())
+	if len(ids) > 0 {
+		tx = tx.Where(clause.IN{Column: clause.PrimaryColumn, Values: ids})
+	}
+	if hasWhere {
+		tx = tx.Clauses(where.Expression)
+	}
+	var targets []interface{}
+	if err := tx.Pluck(primary.DBName, &targets).Error; err != nil {
+		db.AddError(err)
+		return
+	}
+	db.InstanceSet(softDeleteIDsKey, targets)
+}
+
+func (p *SoftDeletePlugin) afterDelete(db *gorm.DB) {
+	if db.Error != nil || db.RowsAffected == 0 {
+		return
+	}
+	value, ok := db.InstanceGet(softDeleteIDsKey)
+	if !ok || len(value.([]interface{})) == 0 {
+		return
+	}
+	ids := value.([]interface{})
+
+	for _, relationship := range cascades(db.Statement.Schema) {
+		child := reflect.New(relationship.FieldSchema.ModelType).Interface()
+		column := clause.Column{Name: relationship.References[0].ForeignKey.DBName}
+		err := db.Session(&gorm.Session{NewDB: true}).Where(clause.IN{Column: column, Values: ids}).Delete(child).Error
+		if err != nil {
+			db.AddError(err)
+			return
+		}
+	}
+}
+
+// RestoreDeleted brings back the soft deleted rows of model with the given
+// primary keys, together with the children SoftDeletePlugin deleted with
+// them. Children deleted before their parent stay deleted. It returns how many
+// of the given rows were restored.
+func RestoreDeleted(ctx context.Context, db *gorm.DB, model interface{}, ids ...interface{}) (int64, error) {
+	tx := FromContext(ctx, db)
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(model); err != nil {
+		return 0, err
+	}
+	if softDeleteField(stmt.Schema) == nil {
+		return 0, ErrSoftDeleteUnsupported
+	}
+
+	var restored int64
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		var err error
+		restored, err = restoreRows(tx, stmt.Schema, clause.IN{Column: clause.PrimaryColumn, Values: ids})
+		return err
+	})
+	return restored, Classify(err)
+}
+
+func restoreRows(tx *gorm.DB, sch *schema.Schema, where clause.Expression) (int64, error) {
+	found := reflect.New(reflect.SliceOf(sch.ModelType))
+	err := tx.Unscoped().Model(reflect.New(sch.ModelType).Interface()).
+		Where(where).Scopes(OnlyDeleted()).Find(found.Interface()).Error
+	if err != nil {
+		return 0, err
+	}
+
+	ctx := tx.Statement.Context
+	deletedAt := softDeleteField(sch)
+	var restored int64
+	for _, row := range rows(found.Elem()) {
+		id, _ := sch.PrioritizedPrimaryField.ValueOf(ctx, row)
+		deleted, _ := deletedAt.ValueOf(ctx, row)
+
+		result := tx.Unscoped().Model(reflect.New(sch.ModelType).Interface()).
+			Where(clause.Eq{Column: clause.PrimaryColumn, Value: id}).Update(deletedAt.DBName, nil)
+		if result.Error != nil {
+			return restored, result.Error
+		}
+		restored += result.RowsAffected
+
+		// The cascade runs after the parent's delete, so its children carry
+		// the same or a later deleted_at.
+		for _, relationship := range cascades(sch) {
+			column := relationship.References[0].ForeignKey.DBName
+			childDeletedAt := softDeleteField(relationship.FieldSchema).DBName
+			_, err := restoreRows(tx, relationship.FieldSchema, clause.And(
+				clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Value: id},
+				clause.Gte{Column: clause.Column{Table: clause.CurrentTable, Name: childDeletedAt}, Value: deleted},
+			))
+			if err != nil {
+				return restored, err
+			}
+		}
+	}
+	return restored, nil
+}
+
+// softDeleteField returns the gorm.DeletedAt field of the schema, if any.
+func softDeleteField(sch *schema.Schema) *schema.Field {
+	for _, field := range sch.Fields {
+		if field.FieldType == reflect.TypeOf(gorm.DeletedAt{}) {
+			return field
+		}
+	}
+	return nil
+}
+
+// cascades returns the has-one and has-many associations of the schema that
+// point at its primary key from a soft deletable model.
+func cascades(sch *schema.Schema) []*schema.Relationship {
+	var relationships []*schema.Relationship
+	for _, relationship := range append(append([]*schema.Relationship{}, sch.Relationships.HasOne...), sch.Relationships.HasMany...) {
+		if len(relationship.References) != 1 || !relationship.References[0].OwnPrimaryKey ||
+			!relationship.References[0].PrimaryKey.PrimaryKey || softDeleteField(relationship.FieldSchema) == nil {
+			continue
+		}
+		relationships = append(relationships, relationship)
+	}
+	return relationships
+}
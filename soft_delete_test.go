@@ -0,0 +1,103 @@
+package golang_gorm
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSoftDeleteCascade(t *testing.T) {
+	db := newTestDB(t, "users", "wallets", "addresses")
+	repositories := NewRepositories(db)
+	ctx := context.Background()
+
+	// Deleted on its own before the user, so restoring the user leaves it.
+	err := repositories.Addresses.Delete(ctx, 1)
+	assert.Nil(t, err)
+
+	err = repositories.Users.Delete(ctx, "22")
+	assert.Nil(t, err)
+	_, err = repositories.Users.FindByID(ctx, "22")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	_, err = repositories.Wallets.FindByID(ctx, "22")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	addresses, err := repositories.Addresses.FindByUserID(ctx, "22")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(addresses))
+
+	deleted, err := repositories.Addresses.FindAll(ctx, OnlyDeleted(), Where("user_id = ?", "22"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(deleted))
+	wallets, err := repositories.Wallets.FindAll(ctx, WithDeleted(), Where("user_id = ?", "22"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(wallets))
+	assert.True(t, wallets[0].DeletedAt.Valid)
+
+	err = repositories.Users.Restore(ctx, "22")
+	assert.Nil(t, err)
+	err = repositories.Users.Restore(ctx, "22")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	user, err := repositories.Users.FindWithRelations(ctx, "22")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(user.Wallets))
+	assert.Equal(t, "22", user.Wallets[0].ID)
+	assert.Equal(t, 1, len(user.Addresses))
+	assert.Equal(t, int64(2), user.Addresses[0].ID)
+
+	trail := auditTrail(t, db, "wallets", "22")
+	assert.Equal(t, 2, len(trail))
+	assert.Equal(t, UserLogDelete, trail[0].Action)
+	assert.Equal(t, UserLogUpdate, trail[1].Action)
+	assert.Contains(t, auditPayload(t, trail[1]), "deleted_at")
+}
+
+func TestSoftDeleteScopes(t *testing.T) {
+	db := newTestDB(t, "users", "products", "user_like_product")
+	products := NewProductRepository(db)
+	ctx := context.Background()
+
+	err := products.Delete(ctx, "P001")
+	assert.Nil(t, err)
+
+	all, err := products.FindAll(ctx, OrderBy("id asc"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(all))
+	all, err = products.FindAll(ctx, WithDeleted(), OrderBy("id asc"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(all))
+	all, err = products.FindAll(ctx, OnlyDeleted())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(all))
+	assert.Equal(t, "P001", all[0].ID)
+
+	liked, err := products.LikedBy(ctx, "2")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(liked))
+
+	err = products.Restore(ctx, "P001")
+	assert.Nil(t, err)
+	liked, err = products.LikedBy(ctx, "2")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(liked))
+}
+
+func TestSoftDeleteUnscoped(t *testing.T) {
+	db := newTestDB(t, "users")
+
+	err := db.Delete(&User{}, "id = ?", "1").Error
+	assert.Nil(t, err)
+	err = db.Unscoped().Delete(&User{}, "id = ?", "1").Error
+	assert.Nil(t, err)
+
+	var count int64
+	err = db.Unscoped().Model(&User{}).Where("id = ?", "1").Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+
+	_, err = RestoreDeleted(context.Background(), db, &Todo{}, 1)
+	assert.Nil(t, err)
+	_, err = RestoreDeleted(context.Background(), db, &GuestBook{}, 1)
+	assert.Equal(t, ErrSoftDeleteUnsupported, err)
+}
@@ -0,0 +1,34 @@
+package golang_gorm
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+type TodoRepository interface {
+	FindByID(ctx context.Context, id uint) (Todo, error)
+	FindByUserID(ctx context.Context, userID string) ([]Todo, error)
+	// FindKeyset lists todos page by page; see Keyset.
+	FindKeyset(ctx context.Context, keyset Keyset, scopes ...Scope) (CursorPage[Todo], error)
+	Create(ctx context.Context, todo *Todo) error
+	Update(ctx context.Context, todo *Todo) error
+	// Delete soft deletes the todo; Restore brings it back.
+	Delete(ctx context.Context, id uint) error
+	Restore(ctx context.Context, id uint) error
+}
+
+type todoRepository struct {
+	*Repository[Todo, uint]
+}
+
+func NewTodoRepository(db *gorm.DB) TodoRepository {
+	return &todoRepository{Repository: NewRepository[Todo, uint](db)}
+}
+
+func (r *todoRepository) FindByUserID(ctx context.Context, userID string) ([]Todo, error) {
+	return r.FindAll(ctx, Where("user_id = ?", userID), OrderBy("id asc"))
+}
+
+func (r *todoRepository) Update(ctx context.Context, todo *Todo) error {
+	return r.Save(ctx, todo)
+}
@@ -0,0 +1,123 @@
+package golang_gorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sort"
+	"time"
+)
+
+var (
+	ErrInvalidAmount       = errors.New("amount must be positive")
+	ErrSameWallet          = errors.New("cannot transfer to the same wallet")
+	ErrInsufficientBalance = errors.New("insufficient balance")
+)
+
+// Transfer is the outcome of TransferService.Transfer. Sent and Received
+// differ when the wallets hold different currencies.
+type Transfer struct {
+	ID       string
+	Sent     Money
+	Received Money
+	Debit    WalletTransaction
+	Credit   WalletTransaction
+}
+
+type TransferService struct {
+	db *gorm.DB
+}
+
+func NewTransferService(db *gorm.DB) *TransferService {
+	return &TransferService{db: db}
+}
+
+// Transfer moves amount, in minor units of the source wallet's currency, to
+// another wallet in a single transaction. Both wallets are locked with SELECT
+// ... FOR UPDATE in ascending ID order, so two opposite transfers wait for
+// each other instead of deadlocking. Between currencies the amount is
+// converted with the latest stored exchange rate. Deadlocks and lock wait
+// timeouts are retried with DefaultRetryPolicy.
+func (s *TransferService) Transfer(ctx context.Context, fromWalletID, toWalletID string, amount int64) (Transfer, error) {
+	if amount <= 0 {
+		return Transfer{}, ErrInvalidAmount
+	}
+	if fromWalletID == toWalletID {
+		return Transfer{}, ErrSameWallet
+	}
+
+	transfer := Transfer{ID: "Transfer-" + DefaultIDGenerator.NewID()}
+	err := RetryTransaction(ctx, s.db, DefaultRetryPolicy, func(tx *gorm.DB) error {
+		wallets, err := lockWallets(tx, fromWalletID, toWalletID)
+		if err != nil {
+			return err
+		}
+		from, to := wallets[fromWalletID], wallets[toWalletID]
+
+		if from.Balance < amount {
+			return fmt.Errorf("wallet %s: %w", from.ID, ErrInsufficientBalance)
+		}
+
+		transfer.Sent = Money{Amount: amount, Currency: from.Currency}
+		transfer.Received, err = ConvertMoney(ctx, tx, transfer.Sent, to.Currency, time.Now())
+		if err != nil {
+			return err
+		}
+		if transfer.Received.Amount <= 0 {
+			return fmt.Errorf("%s is worth nothing in %s: %w", transfer.Sent, to.Currency, ErrInvalidAmount)
+		}
+
+		err = tx.Model(from).Update("balance", from.Balance-transfer.Sent.Amount).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(to).Update("balance", to.Balance+transfer.Received.Amount).Error
+		if err != nil {
+			return err
+		}
+
+		transfer.Debit = WalletTransaction{
+			TransferId: transfer.ID,
+			WalletId:   from.ID,
+			Type:       WalletTransactionDebit,
+			Amount:     transfer.Sent.Amount,
+			Currency:   transfer.Sent.Currency,
+		}
+		transfer.Credit = WalletTransaction{
+			TransferId: transfer.ID,
+			WalletId:   to.ID,
+			Type:       WalletTransactionCredit,
+			Amount:     transfer.Received.Amount,
+			Currency:   transfer.Received.Currency,
+		}
+		entries := []*WalletTransaction{&transfer.Debit, &transfer.Credit}
+		return tx.Omit(clause.Associations).Create(entries).Error
+	})
+	if err != nil {
+		return Transfer{}, err
+	}
+
+	return transfer, nil
+}
+
+// lockWallets locks the wallets one by one in ascending ID order.
+func lockWallets(tx *gorm.DB, ids ...string) (map[string]*Wallet, error) {
+	sorted := append([]string(nil), ids...)
+	sort.Strings(sorted)
+
+	wallets := map[string]*Wallet{}
+	for _, id := range sorted {
+		var wallet Wallet
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&wallet, "id = ?", id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("wallet %s: %w", id, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		wallets[id] = &wallet
+	}
+	return wallets, nil
+}
@@ -0,0 +1,98 @@
+package golang_gorm
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"sync"
+	"testing"
+)
+
+func walletBalance(t *testing.T, db *gorm.DB, id string) int64 {
+	var wallet Wallet
+	err := db.Take(&wallet, "id = ?", id).Error
+	assert.Nil(t, err)
+	return wallet.Balance
+}
+
+func TestTransfer(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+	service := NewTransferService(db)
+
+	transfer, err := service.Transfer(context.Background(), "1", "01", 250000)
+	assert.Nil(t, err)
+	assert.NotEqual(t, "", transfer.ID)
+	assert.Equal(t, int64(999750000), walletBalance(t, db, "1"))
+	assert.Equal(t, int64(1250000), walletBalance(t, db, "01"))
+
+	var entries []WalletTransaction
+	err = db.Where("transfer_id = ?", transfer.ID).Order("id").Find(&entries).Error
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, WalletTransactionDebit, entries[0].Type)
+	assert.Equal(t, "1", entries[0].WalletId)
+	assert.Equal(t, WalletTransactionCredit, entries[1].Type)
+	assert.Equal(t, "01", entries[1].WalletId)
+	assert.Equal(t, entries[0].Amount, entries[1].Amount)
+}
+
+func TestTransferRejected(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+	service := NewTransferService(db)
+	ctx := context.Background()
+
+	_, err := service.Transfer(ctx, "01", "1", 1000001)
+	assert.True(t, errors.Is(err, ErrInsufficientBalance))
+
+	_, err = service.Transfer(ctx, "01", "missing", 1)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	_, err = service.Transfer(ctx, "01", "01", 1)
+	assert.Equal(t, ErrSameWallet, err)
+
+	_, err = service.Transfer(ctx, "01", "1", 0)
+	assert.Equal(t, ErrInvalidAmount, err)
+
+	assert.Equal(t, int64(1000000), walletBalance(t, db, "01"))
+	assert.Equal(t, int64(1000000000), walletBalance(t, db, "1"))
+
+	var count int64
+	err = db.Model(&WalletTransaction{}).Where("type <> ?", WalletTransactionOpening).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestTransferConcurrent(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+	// SQLite has no row locks; one connection serializes the transactions
+	// the way FOR UPDATE does on MySQL.
+	sqlDB, err := db.DB()
+	assert.Nil(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	service := NewTransferService(db)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := service.Transfer(context.Background(), "1", "01", 1000)
+			assert.Nil(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := service.Transfer(context.Background(), "01", "1", 500)
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(1000000000-20*500), walletBalance(t, db, "1"))
+	assert.Equal(t, int64(1000000+20*500), walletBalance(t, db, "01"))
+
+	var count int64
+	err = db.Model(&WalletTransaction{}).Where("type <> ?", WalletTransactionOpening).Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(80), count)
+}
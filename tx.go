@@ -0,0 +1,43 @@
+package golang_gorm
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// FromContext returns the transaction WithinTx stored in ctx, or db when ctx
+// carries none, bound to ctx. Services and repositories look up their
+// connection through it, so work started inside WithinTx joins the
+// transaction instead of running beside it.
+func FromContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// InTx reports whether ctx carries a transaction.
+func InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return ok
+}
+
+// TxManager runs functions inside transactions passed along in their context.
+type TxManager struct {
+	db *gorm.DB
+}
+
+func NewTxManager(db *gorm.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// WithinTx calls fn with a context that carries a transaction, committing it
+// when fn returns nil and rolling it back otherwise. Called again inside fn it
+// opens a savepoint, so an inner failure only undoes the inner work.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return FromContext(ctx, m.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
@@ -0,0 +1,105 @@
+package golang_gorm
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func countUsers(t *testing.T, ctx context.Context, users UserRepository, ids ...string) int {
+	var found int
+	for _, id := range ids {
+		if _, err := users.FindByID(ctx, id); err == nil {
+			found++
+		}
+	}
+	return found
+}
+
+func TestWithinTx(t *testing.T) {
+	db := newTestDB(t)
+	manager := NewTxManager(db)
+	users := NewUserRepository(db)
+	ctx := context.Background()
+	assert.False(t, InTx(ctx))
+
+	err := manager.WithinTx(ctx, func(ctx context.Context) error {
+		assert.True(t, InTx(ctx))
+		return users.Create(ctx, &User{ID: "50", Password: "rahasia", Name: Name{FirstName: "User 50"}})
+	})
+	assert.Nil(t, err)
+
+	failure := errors.New("rollback")
+	err = manager.WithinTx(ctx, func(ctx context.Context) error {
+		err := users.Create(ctx, &User{ID: "51", Password: "rahasia", Name: Name{FirstName: "User 51"}})
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, 1, countUsers(t, ctx, users, "51"))
+		return failure
+	})
+	assert.Equal(t, failure, err)
+	assert.Equal(t, 1, countUsers(t, ctx, users, "50", "51"))
+}
+
+func TestWithinTxSavepoint(t *testing.T) {
+	db := newTestDB(t)
+	manager := NewTxManager(db)
+	users := NewUserRepository(db)
+	ctx := context.Background()
+
+	failure := errors.New("rollback inner")
+	err := manager.WithinTx(ctx, func(ctx context.Context) error {
+		err := users.Create(ctx, &User{ID: "50", Password: "rahasia", Name: Name{FirstName: "User 50"}})
+		if err != nil {
+			return err
+		}
+
+		err = manager.WithinTx(ctx, func(ctx context.Context) error {
+			err := users.Create(ctx, &User{ID: "51", Password: "rahasia", Name: Name{FirstName: "User 51"}})
+			if err != nil {
+				return err
+			}
+			return failure
+		})
+		assert.Equal(t, failure, err)
+
+		// A unit of work joins the transaction as another savepoint.
+		return NewUnitOfWork(db).Do(ctx, func(repositories Repositories) error {
+			return repositories.Users.Create(ctx, &User{ID: "52", Password: "rahasia", Name: Name{FirstName: "User 52"}})
+		})
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, countUsers(t, ctx, users, "50"))
+	assert.Equal(t, 0, countUsers(t, ctx, users, "51"))
+	assert.Equal(t, 1, countUsers(t, ctx, users, "52"))
+}
+
+func TestServicesJoinTx(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+	manager := NewTxManager(db)
+	ctx := context.Background()
+
+	failure := errors.New("rollback")
+	err := manager.WithinTx(ctx, func(ctx context.Context) error {
+		_, err := NewTransferService(db).Transfer(ctx, "1", "01", 250000)
+		if err != nil {
+			return err
+		}
+		_, err = NewWalletService(db).Credit(ctx, "01", 5000, "joined-credit")
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, int64(1255000), walletBalance(t, FromContext(ctx, db), "01"))
+		return failure
+	})
+	assert.Equal(t, failure, err)
+
+	assert.Equal(t, int64(1000000), walletBalance(t, db, "01"))
+	assert.Equal(t, int64(1000000000), walletBalance(t, db, "1"))
+	var operations int64
+	err = db.Model(&WalletOperation{}).Count(&operations).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), operations)
+}
@@ -6,24 +6,59 @@ import (
 )
 
 type User struct {
-	ID           string    `gorm:"primary_key;column:id;<-:create"`
-	Password     string    `gorm:"column:password"`
-	Name         Name      `gorm:"embedded"`
-	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime;<-:create"`
-	UpdatedAt    time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
-	Information  string    `gorm:"-"`
-	Wallet       Wallet    `gorm:"foreignKey:user_id;references:id"`
-	Addresses    []Address `gorm:"foreignKey:user_id;references:id"`
-	LikeProducts []Product `gorm:"many2many:user_like_product;foreignKey:id;joinForeignKey:user_id;references:id;joinReferences:product_id"`
+	ID           string         `gorm:"primary_key;column:id;<-:create"`
+	Password     Password       `gorm:"column:password" json:"-"`
+	Name         Name           `gorm:"embedded"`
+	CreatedAt    time.Time      `gorm:"column:created_at;autoCreateTime;<-:create"`
+	UpdatedAt    time.Time      `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	DeletedAt    gorm.DeletedAt `gorm:"column:deleted_at;index"`
+	Information  string         `gorm:"-"`
+	Wallets      []Wallet       `gorm:"foreignKey:user_id;references:id;constraint:fk_users_wallet,"`
+	Addresses    []Address      `gorm:"foreignKey:user_id;references:id"`
+	LikeProducts []Product      `gorm:"many2many:user_like_product;foreignKey:id;joinForeignKey:user_id;references:id;joinReferences:product_id"`
 }
 
 func (u *User) TableName() string {
 	return "users"
 }
 
+func (u *User) BeforeSave(db *gorm.DB) error {
+	if dest, ok := db.Statement.Dest.(map[string]interface{}); ok {
+		return hashPasswordColumn(dest)
+	}
+	// Updates with a User other than the model: the hook only runs on the
+	// model, so the password to write has to be hashed in the statement.
+	if dest, ok := updatedUser(db.Statement.Dest); ok && dest != u {
+		hashed, err := dest.Password.hash()
+		if err != nil {
+			return err
+		}
+		if hashed != dest.Password {
+			db.Statement.SetColumn("password", hashed)
+		}
+	}
+
+	hashed, err := u.Password.hash()
+	if err != nil {
+		return err
+	}
+	u.Password = hashed
+	return nil
+}
+
+func updatedUser(dest interface{}) (*User, bool) {
+	switch user := dest.(type) {
+	case User:
+		return &user, true
+	case *User:
+		return user, user != nil
+	}
+	return nil, false
+}
+
 func (u *User) BeforeCreate(db *gorm.DB) error {
 	if u.ID == "" {
-		u.ID = "User-" + time.Now().Format("20060102150405")
+		u.ID = "User-" + DefaultIDGenerator.NewID()
 	}
 	return nil
 }
@@ -0,0 +1,19 @@
+package golang_gorm
+
+// UserLogArchive is one batch of user_logs moved out of the hot table by
+// UserLogRetention. Data holds the entries as gzipped NDJSON; FirstId, LastId,
+// CreatedFrom and CreatedTo bound the IDs and CreatedAt values inside it.
+type UserLogArchive struct {
+	ID          int64  `gorm:"primary_key;column:id;autoIncrement"`
+	FirstId     int    `gorm:"column:first_id"`
+	LastId      int    `gorm:"column:last_id"`
+	CreatedFrom int64  `gorm:"column:created_from;index:idx_user_log_archives_created,priority:1"`
+	CreatedTo   int64  `gorm:"column:created_to;index:idx_user_log_archives_created,priority:2"`
+	Entries     int    `gorm:"column:entries"`
+	Data        []byte `gorm:"column:data"`
+	ArchivedAt  int64  `gorm:"column:archived_at;autoCreateTime:milli"`
+}
+
+func (u *UserLogArchive) TableName() string {
+	return "user_log_archives"
+}
@@ -0,0 +1,153 @@
+package golang_gorm
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"gorm.io/gorm"
+	"io"
+	"strconv"
+	"time"
+)
+
+// UserLogQuery filters the audit trail. Empty fields match everything; From
+// and To are Unix milliseconds bounding CreatedAt as [From, To).
+type UserLogQuery struct {
+	UserId     string
+	Actions    []UserLogAction
+	EntityType string
+	EntityId   string
+	From       int64
+	To         int64
+}
+
+// Scope narrows a user_logs query to the filter.
+func (q UserLogQuery) Scope() Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		if q.UserId != "" {
+			db = db.Where("user_id = ?", q.UserId)
+		}
+		if len(q.Actions) > 0 {
+			db = db.Where("action IN ?", q.Actions)
+		}
+		if q.EntityType != "" {
+			db = db.Where("entity_type = ?", q.EntityType)
+		}
+		if q.EntityId != "" {
+			db = db.Where("entity_id = ?", q.EntityId)
+		}
+		if q.From != 0 {
+			db = db.Where("created_at >= ?", q.From)
+		}
+		if q.To != 0 {
+			db = db.Where("created_at < ?", q.To)
+		}
+		return db
+	}
+}
+
+// UserLogExportFormat selects how UserLogRepository.Export writes entries.
+type UserLogExportFormat string
+
+const (
+	UserLogCSV    UserLogExportFormat = "csv"
+	UserLogNDJSON UserLogExportFormat = "ndjson"
+)
+
+// userLogExportBatch is how many entries Export reads at a time.
+const userLogExportBatch = 500
+
+var userLogCSVHeader = []string{"id", "user_id", "action", "entity_type", "entity_id", "created_at", "created_time", "payload"}
+
+type UserLogRepository interface {
+	FindByID(ctx context.Context, id int) (UserLog, error)
+	FindByUserID(ctx context.Context, userID string) ([]UserLog, error)
+	// FindKeyset lists log entries page by page; see Keyset.
+	FindKeyset(ctx context.Context, keyset Keyset, scopes ...Scope) (CursorPage[UserLog], error)
+	// Search returns one page of the entries matching query, newest first
+	// unless keyset orders otherwise.
+	Search(ctx context.Context, query UserLogQuery, keyset Keyset) (CursorPage[UserLog], error)
+	// Export writes every entry matching query to w, oldest first, and
+	// returns how many it wrote.
+	Export(ctx context.Context, w io.Writer, format UserLogExportFormat, query UserLogQuery) (int, error)
+	Create(ctx context.Context, userLog *UserLog) error
+}
+
+type userLogRepository struct {
+	*Repository[UserLog, int]
+}
+
+func NewUserLogRepository(db *gorm.DB) UserLogRepository {
+	return &userLogRepository{Repository: NewRepository[UserLog, int](db)}
+}
+
+func (r *userLogRepository) FindByUserID(ctx context.Context, userID string) ([]UserLog, error) {
+	return r.FindAll(ctx, Where("user_id = ?", userID), OrderBy("created_at asc, id asc"))
+}
+
+func (r *userLogRepository) Search(ctx context.Context, query UserLogQuery, keyset Keyset) (CursorPage[UserLog], error) {
+	if len(keyset.Order) == 0 {
+		keyset.Order = []SortKey{{Column: "created_at", Desc: true}}
+	}
+	return r.FindKeyset(ctx, keyset, query.Scope())
+}
+
+func (r *userLogRepository) Export(ctx context.Context, w io.Writer, format UserLogExportFormat, query UserLogQuery) (int, error) {
+	var write func(log UserLog) error
+	var flush func() error
+	switch format {
+	case UserLogCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(userLogCSVHeader); err != nil {
+			return 0, err
+		}
+		write = func(log UserLog) error {
+			return writer.Write([]string{
+				strconv.Itoa(log.ID),
+				log.UserId,
+				string(log.Action),
+				log.EntityType,
+				log.EntityId,
+				strconv.FormatInt(log.CreatedAt, 10),
+				log.CreatedTime().UTC().Format(time.RFC3339Nano),
+				string(log.Payload),
+			})
+		}
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	case UserLogNDJSON:
+		encoder := json.NewEncoder(w)
+		write = func(log UserLog) error {
+			return encoder.Encode(log)
+		}
+		flush = func() error {
+			return nil
+		}
+	default:
+		return 0, fmt.Errorf("unknown export format %q", format)
+	}
+
+	// Walking the keyset keeps memory flat and, unlike offsets, does not skip
+	// entries that are written while the export runs.
+	written := 0
+	keyset := Keyset{Order: []SortKey{{Column: "created_at"}}, Limit: userLogExportBatch}
+	for {
+		page, err := r.FindKeyset(ctx, keyset, query.Scope())
+		if err != nil {
+			return written, err
+		}
+		for _, log := range page.Items {
+			if err := write(log); err != nil {
+				return written, err
+			}
+			written++
+		}
+		if page.Next == "" {
+			return written, flush()
+		}
+		keyset.Cursor = page.Next
+	}
+}
@@ -0,0 +1,96 @@
+package golang_gorm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func seedUserLogs(t *testing.T, db *gorm.DB) []UserLog {
+	logs := []UserLog{
+		{UserId: "1", Action: UserLogCreate, EntityType: "wallets", EntityId: "1", Payload: JSON(`{"balance":100}`), CreatedAt: 1000},
+		{UserId: "1", Action: UserLogUpdate, EntityType: "wallets", EntityId: "1", Payload: JSON(`{"balance":{"old":100,"new":50}}`), CreatedAt: 2000},
+		{UserId: "2", Action: UserLogCreate, EntityType: "users", EntityId: "2", CreatedAt: 3000},
+		{UserId: "1", Action: UserLogDelete, EntityType: "wallets", EntityId: "1", CreatedAt: 4000},
+		{UserId: "1", Action: "Login", CreatedAt: 5000},
+	}
+	err := db.Create(&logs).Error
+	assert.Nil(t, err)
+	return logs
+}
+
+func userLogIDs(logs []UserLog) []int {
+	ids := make([]int, len(logs))
+	for i, log := range logs {
+		ids[i] = log.ID
+	}
+	return ids
+}
+
+func TestUserLogSearch(t *testing.T) {
+	db := newTestDB(t)
+	logs := seedUserLogs(t, db)
+	repository := NewUserLogRepository(db)
+	ctx := context.Background()
+
+	page, err := repository.Search(ctx, UserLogQuery{UserId: "1"}, Keyset{Limit: 3})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{logs[4].ID, logs[3].ID, logs[1].ID}, userLogIDs(page.Items))
+	page, err = repository.Search(ctx, UserLogQuery{UserId: "1"}, Keyset{Cursor: page.Next, Limit: 3})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{logs[0].ID}, userLogIDs(page.Items))
+	assert.Equal(t, "", page.Next)
+
+	page, err = repository.Search(ctx, UserLogQuery{Actions: []UserLogAction{UserLogCreate, UserLogDelete}}, Keyset{Limit: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{logs[3].ID, logs[2].ID, logs[0].ID}, userLogIDs(page.Items))
+
+	page, err = repository.Search(ctx, UserLogQuery{EntityType: "wallets", EntityId: "1", From: 2000, To: 4000}, Keyset{Limit: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{logs[1].ID}, userLogIDs(page.Items))
+	assert.JSONEq(t, `{"balance":{"old":100,"new":50}}`, string(page.Items[0].Payload))
+}
+
+func TestUserLogExport(t *testing.T) {
+	db := newTestDB(t)
+	logs := seedUserLogs(t, db)
+	repository := NewUserLogRepository(db)
+	ctx := context.Background()
+	query := UserLogQuery{EntityType: "wallets"}
+
+	var buffer bytes.Buffer
+	n, err := repository.Export(ctx, &buffer, UserLogCSV, query)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, n)
+	records, err := csv.NewReader(&buffer).ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(records))
+	assert.Equal(t, userLogCSVHeader, records[0])
+	assert.Equal(t, "create", records[1][2])
+	assert.Equal(t, "1000", records[1][5])
+	assert.Equal(t, "1970-01-01T00:00:01Z", records[1][6])
+	assert.Equal(t, `{"balance":100}`, records[1][7])
+	assert.Equal(t, "", records[3][7])
+
+	buffer.Reset()
+	n, err = repository.Export(ctx, &buffer, UserLogNDJSON, query)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, n)
+	var exported []int
+	scanner := bufio.NewScanner(&buffer)
+	for scanner.Scan() {
+		var log UserLog
+		err := json.Unmarshal(scanner.Bytes(), &log)
+		assert.Nil(t, err)
+		exported = append(exported, log.ID)
+	}
+	assert.Equal(t, []int{logs[0].ID, logs[1].ID, logs[3].ID}, exported)
+
+	_, err = repository.Export(ctx, &buffer, "xml", query)
+	assert.NotNil(t, err)
+}
@@ -1,13 +1,95 @@
 package golang_gorm
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"time"
+)
+
+// UserLogAction is what a UserLog records. AuditPlugin writes the three
+// listed here; hand-written entries may use their own.
+type UserLogAction string
+
+const (
+	UserLogCreate UserLogAction = "create"
+	UserLogUpdate UserLogAction = "update"
+	UserLogDelete UserLogAction = "delete"
+)
+
+// UserLog is one entry of the audit trail. AuditPlugin fills EntityType with
+// the changed table, EntityId with the row's primary key and Payload with the
+// changed columns; entries written by hand may leave them empty. CreatedAt is
+// in Unix milliseconds.
 type UserLog struct {
-	ID        int    `gorm:"primary_key;column:id;autoIncrement"`
-	UserId    string `gorm:"column:user_id"`
-	Action    string `gorm:"column:action"`
-	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
-	UpdatedAt int64  `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	ID         int           `gorm:"primary_key;column:id;autoIncrement" json:"id"`
+	UserId     string        `gorm:"column:user_id;index:idx_user_logs_user,priority:1" json:"user_id"`
+	Action     UserLogAction `gorm:"column:action" json:"action"`
+	EntityType string        `gorm:"column:entity_type;size:100;index:idx_user_logs_entity,priority:1" json:"entity_type,omitempty"`
+	EntityId   string        `gorm:"column:entity_id;size:100;index:idx_user_logs_entity,priority:2" json:"entity_id,omitempty"`
+	Payload    JSON          `gorm:"column:payload" json:"payload,omitempty"`
+	CreatedAt  int64         `gorm:"column:created_at;autoCreateTime:milli;index:idx_user_logs_user,priority:2;index" json:"created_at"`
+	UpdatedAt  int64         `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli" json:"-"`
 }
 
 func (u *UserLog) TableName() string {
 	return "user_logs"
 }
+
+// CreatedTime returns CreatedAt as a time.
+func (u *UserLog) CreatedTime() time.Time {
+	return time.UnixMilli(u.CreatedAt)
+}
+
+// JSON is a JSON document stored in a JSON column on MySQL and as text on
+// SQLite. It marshals as the document itself rather than a string.
+type JSON json.RawMessage
+
+func (j JSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	if !json.Valid(j) {
+		return nil, fmt.Errorf("invalid JSON %q", string(j))
+	}
+	return string(j), nil
+}
+
+func (j *JSON) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case nil:
+		*j = nil
+	case string:
+		*j = JSON(value)
+	case []byte:
+		*j = append(JSON(nil), value...)
+	default:
+		return fmt.Errorf("cannot scan %T into JSON", src)
+	}
+	return nil
+}
+
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+func (j *JSON) UnmarshalJSON(data []byte) error {
+	*j = append(JSON(nil), data...)
+	return nil
+}
+
+func (JSON) GormDataType() string {
+	return "json"
+}
+
+func (JSON) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	if db.Dialector.Name() == "mysql" {
+		return "JSON"
+	}
+	return "TEXT"
+}
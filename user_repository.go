@@ -0,0 +1,79 @@
+package golang_gorm
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+)
+
+type UserRepository interface {
+	FindByID(ctx context.Context, id string) (User, error)
+	// FindAll lists users; pass WithDeleted or OnlyDeleted to see deleted ones.
+	FindAll(ctx context.Context, scopes ...Scope) ([]User, error)
+	// FindWithRelations also loads the wallet, addresses and liked products.
+	FindWithRelations(ctx context.Context, id string) (User, error)
+	// Search matches name against the first, middle and last names.
+	Search(ctx context.Context, name string) ([]User, error)
+	// FindKeyset lists users page by page; see Keyset.
+	FindKeyset(ctx context.Context, keyset Keyset, scopes ...Scope) (CursorPage[User], error)
+	// Create inserts the user together with the wallet and addresses it
+	// carries; liked products are left alone.
+	Create(ctx context.Context, user *User) error
+	Update(ctx context.Context, user *User) error
+	// Delete soft deletes the user together with its wallet and addresses;
+	// Restore brings them back.
+	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
+	LikeProduct(ctx context.Context, userID, productID string) error
+}
+
+type userRepository struct {
+	*Repository[User, string]
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{Repository: NewRepository[User, string](db), db: db}
+}
+
+func (r *userRepository) FindWithRelations(ctx context.Context, id string) (User, error) {
+	var user User
+	err := FromContext(ctx, r.db).Preload("Wallets").Preload("Addresses").Preload("LikeProducts").
+		Take(&user, "id = ?", id).Error
+	return user, Classify(err)
+}
+
+// Search finds the users with name in any part of their name, taken
+// literally: % and _ match only themselves.
+func (r *userRepository) Search(ctx context.Context, name string) ([]User, error) {
+	pattern := "%" + likeEscaper.Replace(name) + "%"
+	// The escape character is bound rather than written as '\', which MySQL
+	// would read as the start of an escape sequence.
+	return r.FindAll(ctx,
+		Where("first_name LIKE ? ESCAPE ? OR middle_name LIKE ? ESCAPE ? OR last_name LIKE ? ESCAPE ?",
+			pattern, `\`, pattern, `\`, pattern, `\`),
+		OrderBy("id asc"))
+}
+
+func (r *userRepository) Create(ctx context.Context, user *User) error {
+	return Classify(FromContext(ctx, r.db).Omit("LikeProducts").Create(user).Error)
+}
+
+// Update saves the user's own columns; associations are left untouched. A
+// missing or soft deleted user is reported as gorm.ErrRecordNotFound.
+func (r *userRepository) Update(ctx context.Context, user *User) error {
+	return r.Save(ctx, user)
+}
+
+func (r *userRepository) LikeProduct(ctx context.Context, userID, productID string) error {
+	// Written to the join table directly so a missing product fails on the
+	// foreign key instead of being created empty by the association.
+	err := FromContext(ctx, r.db).Table("user_like_product").Clauses(clause.OnConflict{DoNothing: true}).
+		Create(map[string]interface{}{"user_id": userID, "product_id": productID}).Error
+	return Classify(err)
+}
+
+// likeEscaper escapes the LIKE wildcards, and the backslash that escapes
+// them, in a pattern used with a backslash ESCAPE.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
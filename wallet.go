@@ -1,16 +1,57 @@
 package golang_gorm
 
-import "time"
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
 
 type Wallet struct {
-	ID        string    `gorm:"primary_key;column:id"`
-	UserId    string    `gorm:"column:user_id"`
-	Balance   int64     `gorm:"column:balance"`
-	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
-	User      *User     `gorm:"foreignKey:user_id;references:id"`
+	ID        string         `gorm:"primary_key;column:id"`
+	UserId    string         `gorm:"column:user_id;size:100;uniqueIndex:idx_wallets_user_id_currency"`
+	Balance   int64          `gorm:"column:balance"`
+	Currency  string         `gorm:"column:currency;size:3;default:IDR;uniqueIndex:idx_wallets_user_id_currency"`
+	CreatedAt time.Time      `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time      `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;index"`
+	User      *User          `gorm:"foreignKey:user_id;references:id"`
 }
 
 func (w *Wallet) TableName() string {
 	return "wallets"
 }
+
+func (w *Wallet) BeforeCreate(db *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = "Wallet-" + DefaultIDGenerator.NewID()
+	}
+	if w.Currency == "" {
+		w.Currency = DefaultCurrency
+	}
+	if _, ok := Currencies[w.Currency]; !ok {
+		return fmt.Errorf("wallet %s: %w %s", w.ID, ErrUnknownCurrency, w.Currency)
+	}
+	return nil
+}
+
+// AfterCreate records the starting balance as the wallet's first movement, so
+// its history adds up to the balance from the beginning.
+func (w *Wallet) AfterCreate(db *gorm.DB) error {
+	if w.Balance == 0 {
+		return nil
+	}
+	return db.Omit(clause.Associations).Create(&WalletTransaction{
+		TransferId: "Opening-" + w.ID,
+		WalletId:   w.ID,
+		Type:       WalletTransactionOpening,
+		Amount:     w.Balance,
+		Currency:   w.Currency,
+		CreatedAt:  w.CreatedAt,
+	}).Error
+}
+
+// Money returns the balance in minor units of the wallet's currency.
+func (w *Wallet) Money() Money {
+	return Money{Amount: w.Balance, Currency: w.Currency}
+}
@@ -0,0 +1,94 @@
+package golang_gorm
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"time"
+)
+
+// signedAmountSQL sums wallet_transactions into a balance.
+const signedAmountSQL = "COALESCE(SUM(CASE WHEN type = '" + WalletTransactionDebit + "' THEN -amount ELSE amount END), 0)"
+
+// Movement is one entry of a WalletStatement with the balance right after it.
+type Movement struct {
+	WalletTransaction
+	BalanceAfter int64
+}
+
+// WalletStatement lists the movements of a wallet in [From, To) between the
+// balance before the first and after the last.
+type WalletStatement struct {
+	WalletId  string
+	Currency  string
+	From      time.Time
+	To        time.Time
+	Opening   int64
+	Movements []Movement
+	Closing   int64
+}
+
+// BalanceAt returns the balance of the wallet at the given moment, including
+// movements made exactly then. It is zero before the wallet existed.
+func (s *WalletService) BalanceAt(ctx context.Context, walletID string, at time.Time) (Money, error) {
+	db := FromContext(ctx, s.db)
+
+	var wallet Wallet
+	err := db.Take(&wallet, "id = ?", walletID).Error
+	if err != nil {
+		return Money{}, err
+	}
+
+	balance, err := balanceBefore(db, walletID, "created_at <= ?", at)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: balance, Currency: wallet.Currency}, nil
+}
+
+// Statement returns the movements of the wallet from from, inclusive, to to,
+// exclusive, in the order they were made.
+func (s *WalletService) Statement(ctx context.Context, walletID string, from, to time.Time) (WalletStatement, error) {
+	if !from.Before(to) {
+		return WalletStatement{}, fmt.Errorf("statement from %s must be before %s", from, to)
+	}
+
+	statement := WalletStatement{WalletId: walletID, From: from, To: to}
+	// One transaction so the opening balance and the movements agree.
+	err := FromContext(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		var wallet Wallet
+		err := tx.Take(&wallet, "id = ?", walletID).Error
+		if err != nil {
+			return err
+		}
+		statement.Currency = wallet.Currency
+
+		statement.Opening, err = balanceBefore(tx, walletID, "created_at < ?", from)
+		if err != nil {
+			return err
+		}
+
+		var entries []WalletTransaction
+		err = tx.Where("wallet_id = ? AND created_at >= ? AND created_at < ?", walletID, from, to).
+			Order("created_at asc, id asc").Find(&entries).Error
+		if err != nil {
+			return err
+		}
+
+		balance := statement.Opening
+		for _, entry := range entries {
+			balance += entry.SignedAmount()
+			statement.Movements = append(statement.Movements, Movement{WalletTransaction: entry, BalanceAfter: balance})
+		}
+		statement.Closing = balance
+		return nil
+	})
+	return statement, err
+}
+
+func balanceBefore(db *gorm.DB, walletID string, query string, at time.Time) (int64, error) {
+	var balance int64
+	err := db.Model(&WalletTransaction{}).Select(signedAmountSQL).
+		Where("wallet_id = ?", walletID).Where(query, at).Scan(&balance).Error
+	return balance, err
+}
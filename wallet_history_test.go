@@ -0,0 +1,93 @@
+package golang_gorm
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWalletBalanceAt(t *testing.T) {
+	db := newTestDB(t, "users")
+	service := NewWalletService(db)
+	ctx := context.Background()
+
+	opened := time.Now().Add(-time.Hour).Truncate(time.Second)
+	wallet := Wallet{UserId: "2", Balance: 100000, CreatedAt: opened}
+	err := db.Create(&wallet).Error
+	assert.Nil(t, err)
+
+	_, err = service.Credit(ctx, wallet.ID, 50000, "history-credit")
+	assert.Nil(t, err)
+	_, err = service.Debit(ctx, wallet.ID, 20000, "history-debit")
+	assert.Nil(t, err)
+
+	balance, err := service.BalanceAt(ctx, wallet.ID, opened.Add(-time.Second))
+	assert.Nil(t, err)
+	assert.Equal(t, Money{Amount: 0, Currency: "IDR"}, balance)
+
+	balance, err = service.BalanceAt(ctx, wallet.ID, opened)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(100000), balance.Amount)
+
+	balance, err = service.BalanceAt(ctx, wallet.ID, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(130000), balance.Amount)
+	assert.Equal(t, walletBalance(t, db, wallet.ID), balance.Amount)
+}
+
+func TestWalletStatement(t *testing.T) {
+	db := newTestDB(t, "users")
+	service := NewWalletService(db)
+	ctx := context.Background()
+
+	opened := time.Now().Add(-time.Hour).Truncate(time.Second)
+	wallet := Wallet{UserId: "2", Balance: 100000, CreatedAt: opened}
+	err := db.Create(&wallet).Error
+	assert.Nil(t, err)
+
+	_, err = service.Credit(ctx, wallet.ID, 50000, "statement-credit")
+	assert.Nil(t, err)
+	_, err = service.Debit(ctx, wallet.ID, 20000, "statement-debit")
+	assert.Nil(t, err)
+
+	statement, err := service.Statement(ctx, wallet.ID, opened.Add(time.Second), time.Now().Add(time.Second))
+	assert.Nil(t, err)
+	assert.Equal(t, "IDR", statement.Currency)
+	assert.Equal(t, int64(100000), statement.Opening)
+	assert.Equal(t, 2, len(statement.Movements))
+	assert.Equal(t, WalletTransactionCredit, statement.Movements[0].Type)
+	assert.Equal(t, int64(150000), statement.Movements[0].BalanceAfter)
+	assert.Equal(t, WalletTransactionDebit, statement.Movements[1].Type)
+	assert.Equal(t, int64(130000), statement.Movements[1].BalanceAfter)
+	assert.Equal(t, int64(130000), statement.Closing)
+
+	statement, err = service.Statement(ctx, wallet.ID, opened, opened.Add(time.Second))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), statement.Opening)
+	assert.Equal(t, 1, len(statement.Movements))
+	assert.Equal(t, WalletTransactionOpening, statement.Movements[0].Type)
+	assert.Equal(t, int64(100000), statement.Closing)
+
+	_, err = service.Statement(ctx, wallet.ID, opened, opened)
+	assert.NotNil(t, err)
+}
+
+func TestWalletMovementsImmutable(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+
+	var entry WalletTransaction
+	err := db.Take(&entry, "wallet_id = ?", "1").Error
+	assert.Nil(t, err)
+	assert.Equal(t, WalletTransactionOpening, entry.Type)
+	assert.Equal(t, int64(1000000000), entry.Amount)
+
+	err = db.Model(&entry).Update("amount", 1).Error
+	assert.Equal(t, ErrImmutableMovement, err)
+	err = db.Delete(&entry).Error
+	assert.Equal(t, ErrImmutableMovement, err)
+
+	err = db.Take(&entry, "id = ?", entry.ID).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1000000000), entry.Amount)
+}
@@ -0,0 +1,21 @@
+package golang_gorm
+
+import "time"
+
+// WalletOperation remembers the outcome of a Credit or Debit under the
+// caller's idempotency key, so a retried request replays it instead of moving
+// money again.
+type WalletOperation struct {
+	ID             int64     `gorm:"primary_key;column:id;autoIncrement"`
+	IdempotencyKey string    `gorm:"column:idempotency_key;size:100;uniqueIndex"`
+	WalletId       string    `gorm:"column:wallet_id;index"`
+	Type           string    `gorm:"column:type"`
+	Amount         int64     `gorm:"column:amount"`
+	BalanceAfter   int64     `gorm:"column:balance_after"`
+	CreatedAt      time.Time `gorm:"column:created_at;autoCreateTime"`
+	Wallet         *Wallet   `gorm:"foreignKey:wallet_id;references:id"`
+}
+
+func (w *WalletOperation) TableName() string {
+	return "wallet_operations"
+}
@@ -0,0 +1,35 @@
+package golang_gorm
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+// WalletRepository reads wallets. Balances only change through TransferService
+// and WalletService, which keep the movement ledger in step.
+type WalletRepository interface {
+	FindByID(ctx context.Context, id string) (Wallet, error)
+	// FindAll lists wallets; pass WithDeleted or OnlyDeleted to see deleted
+	// ones.
+	FindAll(ctx context.Context, scopes ...Scope) ([]Wallet, error)
+	FindByUserID(ctx context.Context, userID string) ([]Wallet, error)
+	// FindByBalance returns the wallets holding between min and max, inclusive.
+	FindByBalance(ctx context.Context, min, max int64) ([]Wallet, error)
+	Create(ctx context.Context, wallet *Wallet) error
+}
+
+type walletRepository struct {
+	*Repository[Wallet, string]
+}
+
+func NewWalletRepository(db *gorm.DB) WalletRepository {
+	return &walletRepository{Repository: NewRepository[Wallet, string](db)}
+}
+
+func (r *walletRepository) FindByUserID(ctx context.Context, userID string) ([]Wallet, error) {
+	return r.FindAll(ctx, Where("user_id = ?", userID), OrderBy("currency asc"))
+}
+
+func (r *walletRepository) FindByBalance(ctx context.Context, min, max int64) ([]Wallet, error) {
+	return r.FindAll(ctx, Where("balance BETWEEN ? AND ?", min, max), OrderBy("id asc"))
+}
@@ -0,0 +1,151 @@
+package golang_gorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrMissingIdempotencyKey = errors.New("idempotency key is required")
+	ErrIdempotencyKeyReused  = errors.New("idempotency key was used for a different operation")
+)
+
+type WalletService struct {
+	db *gorm.DB
+}
+
+func NewWalletService(db *gorm.DB) *WalletService {
+	return &WalletService{db: db}
+}
+
+// OpenWallet returns the user's wallet in currency, creating an empty one if
+// the user has none yet and restoring it if it was soft deleted, as a user
+// has one wallet per currency. The user row is locked so two concurrent calls
+// cannot both create a wallet.
+func (s *WalletService) OpenWallet(ctx context.Context, userID, currency string) (Wallet, error) {
+	if _, ok := Currencies[currency]; !ok {
+		return Wallet{}, fmt.Errorf("%w %s", ErrUnknownCurrency, currency)
+	}
+
+	var wallet Wallet
+	err := RetryTransaction(ctx, s.db, DefaultRetryPolicy, func(tx *gorm.DB) error {
+		var user User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&user, "id = ?", userID).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Unscoped().Where("user_id = ? AND currency = ?", userID, currency).Take(&wallet).Error
+		if err == nil && wallet.DeletedAt.Valid {
+			wallet.DeletedAt = gorm.DeletedAt{}
+			return tx.Unscoped().Model(&wallet).Update("deleted_at", nil).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		wallet = Wallet{UserId: userID, Currency: currency}
+		return tx.Omit(clause.Associations).Create(&wallet).Error
+	})
+	return wallet, err
+}
+
+// Wallets returns every wallet of the user, one per currency.
+func (s *WalletService) Wallets(ctx context.Context, userID string) ([]Wallet, error) {
+	var wallets []Wallet
+	err := FromContext(ctx, s.db).Where("user_id = ?", userID).Order("currency asc").Find(&wallets).Error
+	return wallets, err
+}
+
+// Credit adds amount, in minor units of the wallet's currency, to the
+// wallet. Calling it again with the same
+// idempotency key returns the first result without crediting twice.
+func (s *WalletService) Credit(ctx context.Context, walletID string, amount int64, idempotencyKey string) (WalletOperation, error) {
+	return s.apply(ctx, walletID, WalletTransactionCredit, amount, idempotencyKey)
+}
+
+// Debit takes amount from the wallet, failing with ErrInsufficientBalance
+// rather than going negative. Retries with the same key are replayed like
+// Credit.
+func (s *WalletService) Debit(ctx context.Context, walletID string, amount int64, idempotencyKey string) (WalletOperation, error) {
+	return s.apply(ctx, walletID, WalletTransactionDebit, amount, idempotencyKey)
+}
+
+func (s *WalletService) apply(ctx context.Context, walletID, kind string, amount int64, idempotencyKey string) (WalletOperation, error) {
+	if amount <= 0 {
+		return WalletOperation{}, ErrInvalidAmount
+	}
+	if idempotencyKey == "" {
+		return WalletOperation{}, ErrMissingIdempotencyKey
+	}
+
+	request := WalletOperation{IdempotencyKey: idempotencyKey, WalletId: walletID, Type: kind, Amount: amount}
+	if existing, err := s.find(ctx, idempotencyKey); err == nil {
+		return replay(existing, request)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return WalletOperation{}, err
+	}
+
+	var operation WalletOperation
+	err := RetryTransaction(ctx, s.db, DefaultRetryPolicy, func(tx *gorm.DB) error {
+		operation = request
+		wallets, err := lockWallets(tx, walletID)
+		if err != nil {
+			return err
+		}
+		wallet := wallets[walletID]
+
+		balance := wallet.Balance + amount
+		if kind == WalletTransactionDebit {
+			balance = wallet.Balance - amount
+		}
+		if balance < 0 {
+			return fmt.Errorf("wallet %s: %w", wallet.ID, ErrInsufficientBalance)
+		}
+
+		// The unique key is claimed first: a concurrent retry blocks on it
+		// and fails instead of touching the balance.
+		operation.BalanceAfter = balance
+		err = tx.Omit(clause.Associations).Create(&operation).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(wallet).Update("balance", balance).Error
+		if err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).Create(&WalletTransaction{
+			TransferId: fmt.Sprintf("Operation-%d", operation.ID),
+			WalletId:   wallet.ID,
+			Type:       kind,
+			Amount:     amount,
+			Currency:   wallet.Currency,
+		}).Error
+	})
+	if err != nil {
+		// Lost the race against a retry carrying the same key.
+		if existing, findErr := s.find(ctx, idempotencyKey); findErr == nil {
+			return replay(existing, request)
+		}
+		return WalletOperation{}, err
+	}
+
+	return operation, nil
+}
+
+func (s *WalletService) find(ctx context.Context, idempotencyKey string) (WalletOperation, error) {
+	var operation WalletOperation
+	err := FromContext(ctx, s.db).Take(&operation, "idempotency_key = ?", idempotencyKey).Error
+	return operation, err
+}
+
+func replay(existing, request WalletOperation) (WalletOperation, error) {
+	if existing.WalletId != request.WalletId || existing.Type != request.Type || existing.Amount != request.Amount {
+		return WalletOperation{}, fmt.Errorf("%w: %s", ErrIdempotencyKeyReused, request.IdempotencyKey)
+	}
+	return existing, nil
+}
@@ -0,0 +1,79 @@
+package golang_gorm
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestWalletCreditIdempotent(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+	service := NewWalletService(db)
+	ctx := context.Background()
+
+	first, err := service.Credit(ctx, "01", 5000, "topup-1")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1005000), first.BalanceAfter)
+
+	retry, err := service.Credit(ctx, "01", 5000, "topup-1")
+	assert.Nil(t, err)
+	assert.Equal(t, first.ID, retry.ID)
+	assert.Equal(t, int64(1005000), retry.BalanceAfter)
+	assert.Equal(t, int64(1005000), walletBalance(t, db, "01"))
+
+	_, err = service.Credit(ctx, "01", 6000, "topup-1")
+	assert.True(t, errors.Is(err, ErrIdempotencyKeyReused))
+
+	_, err = service.Credit(ctx, "01", 5000, "")
+	assert.Equal(t, ErrMissingIdempotencyKey, err)
+
+	var entries int64
+	err = db.Model(&WalletTransaction{}).Where("wallet_id = ? AND type <> ?", "01", WalletTransactionOpening).Count(&entries).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), entries)
+}
+
+func TestWalletDebit(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+	service := NewWalletService(db)
+	ctx := context.Background()
+
+	_, err := service.Debit(ctx, "01", 2000000, "purchase-1")
+	assert.True(t, errors.Is(err, ErrInsufficientBalance))
+
+	operation, err := service.Debit(ctx, "01", 400000, "purchase-1")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(600000), operation.BalanceAfter)
+
+	_, err = service.Debit(ctx, "01", 400000, "purchase-1")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(600000), walletBalance(t, db, "01"))
+}
+
+func TestWalletCreditConcurrentRetries(t *testing.T) {
+	db := newTestDB(t, "users", "wallets")
+	sqlDB, err := db.DB()
+	assert.Nil(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	service := NewWalletService(db)
+	results := make([]WalletOperation, 10)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			operation, err := service.Credit(context.Background(), "01", 1000, "topup-2")
+			assert.Nil(t, err)
+			results[i] = operation
+		}(i)
+	}
+	wg.Wait()
+
+	for _, result := range results {
+		assert.Equal(t, results[0].ID, result.ID)
+	}
+	assert.Equal(t, int64(1001000), walletBalance(t, db, "01"))
+}
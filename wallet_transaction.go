@@ -0,0 +1,57 @@
+package golang_gorm
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	WalletTransactionDebit   = "debit"
+	WalletTransactionCredit  = "credit"
+	WalletTransactionOpening = "opening"
+)
+
+var ErrImmutableMovement = errors.New("wallet movements cannot be changed or deleted")
+
+// WalletTransaction is one ledger entry. Every transfer writes a debit on the
+// source wallet and a credit on the destination wallet, both sharing the
+// transfer's ID; a single Credit or Debit on WalletService writes one entry
+// whose TransferId is "Operation-" and the operation's ID. Amount is in minor
+// units of Currency, the currency of the entry's wallet, so the two sides of a
+// transfer between currencies hold different amounts.
+//
+// Entries are the wallet's movement history: a wallet created with a balance
+// gets an opening entry, and the balance at any moment is the signed sum of
+// the entries up to it. Entries are never updated or deleted; a correction is
+// a new entry.
+type WalletTransaction struct {
+	ID         int64     `gorm:"primary_key;column:id;autoIncrement"`
+	TransferId string    `gorm:"column:transfer_id;index"`
+	WalletId   string    `gorm:"column:wallet_id;index;index:idx_wallet_transactions_history,priority:1"`
+	Type       string    `gorm:"column:type"`
+	Amount     int64     `gorm:"column:amount"`
+	Currency   string    `gorm:"column:currency;size:3"`
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime;index:idx_wallet_transactions_history,priority:2"`
+	Wallet     *Wallet   `gorm:"foreignKey:wallet_id;references:id"`
+}
+
+func (w *WalletTransaction) TableName() string {
+	return "wallet_transactions"
+}
+
+// SignedAmount is the entry's effect on the wallet balance.
+func (w *WalletTransaction) SignedAmount() int64 {
+	if w.Type == WalletTransactionDebit {
+		return -w.Amount
+	}
+	return w.Amount
+}
+
+func (w *WalletTransaction) BeforeUpdate(db *gorm.DB) error {
+	return ErrImmutableMovement
+}
+
+func (w *WalletTransaction) BeforeDelete(db *gorm.DB) error {
+	return ErrImmutableMovement
+}